@@ -7,6 +7,11 @@ import (
 // prints the version message
 const version = "v0.0.6"
 
+// Version returns the vulntechfinder version
+func Version() string {
+	return version
+}
+
 func PrintVersion() {
 	fmt.Printf("Current vulntechfinder version %s\n", version)
 }
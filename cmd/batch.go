@@ -0,0 +1,58 @@
+package cmd
+
+import "strings"
+
+// hostBatch is a group of hosts sharing the same filtered techs, scanned by one --batch-size invocation
+type hostBatch struct {
+	techs []string
+	hosts []string
+}
+
+// hostBatcher collects hosts per tech signature for --batch-size and hands a batch back as soon as it is full
+type hostBatcher struct {
+	size          int
+	caseSensitive bool // --case-sensitive: techs differing only in case are batched apart
+	batches       map[string]*hostBatch
+	order         []string
+}
+
+// newHostBatcher returns nil when size is 0 so callers can skip batching entirely
+func newHostBatcher(size int, caseSensitive bool) *hostBatcher {
+	if size <= 0 {
+		return nil
+	}
+	return &hostBatcher{size: size, caseSensitive: caseSensitive, batches: make(map[string]*hostBatch)}
+}
+
+// add queues host under its techs and returns the batch once it holds size hosts
+func (b *hostBatcher) add(host string, techs []string) (hostBatch, bool) {
+	key := techCase(strings.Join(techs, ","), b.caseSensitive)
+	batch, ok := b.batches[key]
+	if !ok {
+		batch = &hostBatch{techs: techs}
+		b.batches[key] = batch
+		b.order = append(b.order, key)
+	}
+	batch.hosts = append(batch.hosts, host)
+	if len(batch.hosts) < b.size {
+		return hostBatch{}, false
+	}
+
+	full := *batch
+	batch.hosts = nil
+	return full, true
+}
+
+// flush returns the partially filled batches left at the end of the input, in the order their techs were first seen
+func (b *hostBatcher) flush() []hostBatch {
+	if b == nil {
+		return nil
+	}
+	var rest []hostBatch
+	for _, key := range b.order {
+		if batch := b.batches[key]; len(batch.hosts) > 0 {
+			rest = append(rest, *batch)
+		}
+	}
+	return rest
+}
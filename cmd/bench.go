@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// benchCmd represents the bench command
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Measure job throughput at several --parallel values using a no-op command over synthetic hosts.",
+	Long: `The 'bench' command runs a cheap command template (default 'echo {host}') for a number of synthetic hosts at each of the given concurrency levels and prints the jobs per second, to help pick --parallel for this machine.
+
+It only measures how fast this machine spawns and reaps the job processes: their output is discarded, so the time the scan commands spend reading, filtering and writing job output is not included.
+
+Examples:
+  vulntechfinder bench
+  vulntechfinder bench --jobs 1000 --parallel 10,50,100,200
+  vulntechfinder bench --cmd "curl -s -o /dev/null http://127.0.0.1:8080/?h={host}"
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		benchCmdStr, _ := cmd.Flags().GetString("cmd")
+		jobCount, _ := cmd.Flags().GetInt("jobs")
+		parallelList, _ := cmd.Flags().GetString("parallel")
+
+		var levels []int
+		for _, value := range strings.Split(parallelList, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n < 1 {
+				fmt.Printf("Error: invalid --parallel value %q\n", value)
+				os.Exit(1)
+			}
+			levels = append(levels, n)
+		}
+		if jobCount < 1 {
+			fmt.Println("Error: --jobs must be at least 1")
+			os.Exit(1)
+		}
+
+		fmt.Printf("%-10s %-8s %-8s %-12s %s\n", "parallel", "jobs", "failed", "elapsed", "jobs/s")
+		for _, parallel := range levels {
+			elapsed, failed := benchRun(benchCmdStr, jobCount, parallel)
+			fmt.Printf("%-10d %-8d %-8d %-12s %.1f\n", parallel, jobCount, failed, elapsed.Round(time.Millisecond), float64(jobCount)/elapsed.Seconds())
+		}
+	},
+}
+
+// benchRun runs template for jobCount synthetic hosts with at most parallel jobs at once, discarding their output,
+// and returns the elapsed time and the number of failed jobs
+func benchRun(template string, jobCount, parallel int) (time.Duration, int) {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	failed := 0
+	semaphore := make(chan struct{}, parallel)
+
+	start := time.Now()
+	for i := 0; i < jobCount; i++ {
+		host := fmt.Sprintf("bench-%d.example.com", i)
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			cmd := exec.Command("sh", "-c", strings.Replace(template, placeholderHost, host, -1))
+			cmd.Stdin = strings.NewReader(host)
+			if err := cmd.Run(); err != nil {
+				mu.Lock()
+				failed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return time.Since(start), failed
+}
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+
+	benchCmd.Flags().StringP("cmd", "c", "echo {host}", "Command template run per synthetic host")
+	benchCmd.Flags().Int("jobs", 500, "Number of synthetic hosts run at each concurrency level")
+	benchCmd.Flags().String("parallel", "1,10,50,100,200", "Comma-separated concurrency levels to measure")
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Number of most recent jobs the --error-threshold failure rate is computed over, and how many of them must
+// have finished before the breaker can trip
+const (
+	breakerWindow  = 20
+	breakerMinJobs = 10
+)
+
+// circuitBreaker pauses launching jobs for a backoff period while the failure rate of the last jobs is above
+// --error-threshold, e.g. while the network is down. A nil *circuitBreaker never trips.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold float64
+	backoff   time.Duration
+	results   []bool // outcomes of the last jobs, true for a failure
+}
+
+// newCircuitBreaker returns nil when threshold is 0 so the breaker is disabled
+func newCircuitBreaker(threshold float64, backoff time.Duration) *circuitBreaker {
+	if threshold <= 0 {
+		return nil
+	}
+	return &circuitBreaker{threshold: threshold, backoff: backoff}
+}
+
+// record adds the outcome of a finished job to the window
+func (b *circuitBreaker) record(failed bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.results = append(b.results, failed)
+	if len(b.results) > breakerWindow {
+		b.results = b.results[len(b.results)-breakerWindow:]
+	}
+}
+
+// tripped returns the failure rate of the window and whether it is above the threshold
+func (b *circuitBreaker) tripped() (float64, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.results) < breakerMinJobs {
+		return 0, false
+	}
+	failures := 0
+	for _, failed := range b.results {
+		if failed {
+			failures++
+		}
+	}
+	rate := float64(failures) / float64(len(b.results))
+	return rate, rate > b.threshold
+}
+
+// wait pauses for the backoff period when the breaker is tripped. The window is then cleared, so the next jobs
+// probe whether failures have subsided and trip it again if they haven't.
+func (b *circuitBreaker) wait(ctx context.Context) {
+	if b == nil {
+		return
+	}
+	rate, tripped := b.tripped()
+	if !tripped {
+		return
+	}
+
+	fmt.Printf("Warning: %.0f%% of the last jobs failed (--error-threshold %.0f%%), pausing new jobs for %s\n", rate*100, b.threshold*100, b.backoff)
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(b.backoff):
+	}
+
+	b.mu.Lock()
+	b.results = nil
+	b.mu.Unlock()
+	fmt.Println("Resuming after the --error-threshold pause")
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// expandCIDRHost returns the addresses of host when it is a CIDR range such as 192.168.0.0/24, for
+// --expand-cidr, and whether it is one. Ranges of more than max addresses are refused with an error.
+func expandCIDRHost(host string, max int) ([]string, bool, error) {
+	prefix, err := netip.ParsePrefix(host)
+	if err != nil {
+		return nil, false, nil
+	}
+	prefix = prefix.Masked()
+
+	if bits := prefix.Addr().BitLen() - prefix.Bits(); bits >= 62 || 1<<bits > max {
+		return nil, true, fmt.Errorf("%s has more than %d addresses (--expand-cidr-max)", host, max)
+	}
+	var addrs []string
+	for addr := prefix.Addr(); prefix.Contains(addr); addr = addr.Next() {
+		addrs = append(addrs, addr.String())
+		if !addr.Next().IsValid() {
+			break
+		}
+	}
+	return addrs, true, nil
+}
@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+)
+
+// readCmdFile reads a command template from path for --cmd-file, dropping the trailing newline
+func readCmdFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// commandFlag describes a flag of a subcommand
+type commandFlag struct {
+	Name      string `json:"name"`
+	Shorthand string `json:"shorthand,omitempty"`
+	Type      string `json:"type"`
+	Default   string `json:"default"`
+	Usage     string `json:"usage"`
+}
+
+// commandInfo describes a registered subcommand and its flags
+type commandInfo struct {
+	Name     string        `json:"name"`
+	Path     string        `json:"path"`
+	Short    string        `json:"short"`
+	Hidden   bool          `json:"hidden,omitempty"`
+	Flags    []commandFlag `json:"flags"`
+	Commands []commandInfo `json:"commands,omitempty"`
+}
+
+// describeCommand walks the command tree below c, including inherited flags so each entry is complete
+func describeCommand(c *cobra.Command) commandInfo {
+	info := commandInfo{Name: c.Name(), Path: c.CommandPath(), Short: c.Short, Hidden: c.Hidden, Flags: []commandFlag{}}
+	c.Flags().VisitAll(func(f *pflag.Flag) {
+		info.Flags = append(info.Flags, commandFlag{f.Name, f.Shorthand, f.Value.Type(), f.DefValue, f.Usage})
+	})
+	c.InheritedFlags().VisitAll(func(f *pflag.Flag) {
+		info.Flags = append(info.Flags, commandFlag{f.Name, f.Shorthand, f.Value.Type(), f.DefValue, f.Usage})
+	})
+	for _, sub := range c.Commands() {
+		info.Commands = append(info.Commands, describeCommand(sub))
+	}
+	return info
+}
+
+// commandsCmd represents the hidden commands command
+var commandsCmd = &cobra.Command{
+	Use:    "commands",
+	Short:  "List the registered subcommands with their flags, types and defaults.",
+	Hidden: true,
+	Long: `The hidden 'commands' command prints each subcommand and its flags, one flag per line separated by tabs, or the whole command tree as JSON with --json, for wrapper scripts and documentation generators.
+
+Examples:
+  vulntechfinder commands
+  vulntechfinder commands --json
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		asJSON, _ := cmd.Flags().GetBool("json")
+		tree := describeCommand(rootCmd)
+
+		if asJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(tree); err != nil {
+				fmt.Printf("Error encoding commands: %s\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		var emitFlags func(info commandInfo)
+		emitFlags = func(info commandInfo) {
+			for _, f := range info.Flags {
+				fmt.Printf("%s\t--%s\t%s\t%s\n", info.Path, f.Name, f.Type, f.Default)
+			}
+			for _, sub := range info.Commands {
+				emitFlags(sub)
+			}
+		}
+		emitFlags(tree)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(commandsCmd)
+
+	commandsCmd.Flags().Bool("json", false, "Print the command tree as JSON")
+}
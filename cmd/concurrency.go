@@ -0,0 +1,18 @@
+package cmd
+
+import "runtime"
+
+// --concurrency-auto sizing: jobs per CPU, since children mostly wait on the network, and an upper bound
+const (
+	autoConcurrencyPerCPU = 4
+	autoConcurrencyMax    = 200
+)
+
+// autoConcurrency returns the number of parallel jobs for --concurrency-auto
+func autoConcurrency() int {
+	n := runtime.NumCPU() * autoConcurrencyPerCPU
+	if n > autoConcurrencyMax {
+		n = autoConcurrencyMax
+	}
+	return n
+}
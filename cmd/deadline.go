@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Exit code used when --max-runtime stops a scan before all jobs ran
+const exitMaxRuntime = 3
+
+// runContext returns the root context of a scan that every job derives from; it expires after maxRuntime
+// (0 for no deadline), after which no new jobs start and running commands are killed
+func runContext(maxRuntime time.Duration) (context.Context, context.CancelFunc) {
+	if maxRuntime <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), maxRuntime)
+}
+
+// Exit code used when --abort-on-first-error stops a scan after a job failed
+const exitJobError = 4
+
+// jobAbort stops the whole scan when the first job fails, for --abort-on-first-error. A nil *jobAbort never
+// aborts, so jobs report their failures to it unconditionally.
+type jobAbort struct {
+	once    sync.Once
+	cancel  context.CancelFunc
+	tripped atomic.Bool
+}
+
+// newJobAbort returns nil unless enabled; cancel is the root context's cancel function
+func newJobAbort(enabled bool, cancel context.CancelFunc) *jobAbort {
+	if !enabled {
+		return nil
+	}
+	return &jobAbort{cancel: cancel}
+}
+
+// fail reports that the job jobKey failed with err; the first failure cancels the scan
+func (a *jobAbort) fail(jobKey string, err error) {
+	if a == nil {
+		return
+	}
+	a.once.Do(func() {
+		fmt.Printf("Error: %s failed: %s, stopping the scan (--abort-on-first-error)\n", jobKey, err)
+		a.tripped.Store(true)
+		a.cancel()
+	})
+}
+
+// failed reports whether a job failure stopped the scan
+func (a *jobAbort) failed() bool {
+	return a != nil && a.tripped.Load()
+}
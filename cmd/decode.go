@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+)
+
+// recordDecoder yields the input records one at a time; *json.Decoder, *parallelDecoder and *strictDecoder implement it
+type recordDecoder interface {
+	Decode(v interface{}) error
+}
+
+// decodeResult is a decoded record or the error decoding it
+type decodeResult[T any] struct {
+	value T
+	err   error
+}
+
+// decodeJob is one NDJSON line waiting for a decode worker
+type decodeJob[T any] struct {
+	line   []byte
+	result chan decodeResult[T]
+}
+
+// parallelDecoder splits NDJSON input into lines on one goroutine and unmarshals them on a pool of workers
+// for --fast-decode, while Decode still returns the records in input order
+type parallelDecoder[T any] struct {
+	results chan chan decodeResult[T]
+}
+
+// newParallelDecoder starts reading r with one decode worker per CPU
+func newParallelDecoder[T any](r io.Reader) *parallelDecoder[T] {
+	workers := runtime.NumCPU()
+	d := &parallelDecoder[T]{results: make(chan chan decodeResult[T], workers*4)}
+	lines := make(chan decodeJob[T], workers*4)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for job := range lines {
+				var value T
+				err := json.Unmarshal(job.line, &value)
+				job.result <- decodeResult[T]{value: value, err: err}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(d.results)
+		defer close(lines)
+
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			result := make(chan decodeResult[T], 1)
+			lines <- decodeJob[T]{line: append([]byte(nil), line...), result: result}
+			d.results <- result
+		}
+		if err := scanner.Err(); err != nil {
+			result := make(chan decodeResult[T], 1)
+			result <- decodeResult[T]{err: err}
+			d.results <- result
+		}
+	}()
+	return d
+}
+
+// Decode stores the next record in v, which must be a *T, or returns io.EOF at the end of the input
+func (d *parallelDecoder[T]) Decode(v interface{}) error {
+	result, ok := <-d.results
+	if !ok {
+		return io.EOF
+	}
+	decoded := <-result
+	if decoded.err != nil {
+		return decoded.err
+	}
+	*v.(*T) = decoded.value
+	return nil
+}
+
+// strictTechRecord holds the only fields --strict-json accepts in input records: those of techfinder output,
+// which both nuclei and httpx read
+type strictTechRecord struct {
+	Host  string   `json:"host"`
+	Tech  []string `json:"tech"`
+	Count int      `json:"count"`
+}
+
+// strictDecoder checks each record against the schema T before decoding it, for --strict-json. Unknown fields,
+// wrong types and records without a host are errors that quote the offending record.
+type strictDecoder[T any] struct {
+	dec *json.Decoder
+}
+
+func newStrictDecoder[T any](r io.Reader) *strictDecoder[T] {
+	return &strictDecoder[T]{dec: json.NewDecoder(r)}
+}
+
+// Decode stores the next record in v or returns io.EOF at the end of the input
+func (d *strictDecoder[T]) Decode(v interface{}) error {
+	var raw json.RawMessage
+	if err := d.dec.Decode(&raw); err != nil {
+		return err
+	}
+
+	var schema T
+	strict := json.NewDecoder(bytes.NewReader(raw))
+	strict.DisallowUnknownFields()
+	if err := strict.Decode(&schema); err != nil {
+		return fmt.Errorf("%s in record %s", err, raw)
+	}
+	var host struct {
+		Host string `json:"host"`
+	}
+	if json.Unmarshal(raw, &host); host.Host == "" {
+		return fmt.Errorf("missing host in record %s", raw)
+	}
+	return json.Unmarshal(raw, v)
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	// ANSI color sequences, so colored and plain copies of a line compare equal
+	ansiColorRegex = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	// Bracketed timestamps like "[2024-01-02 15:04:05]" that nuclei prints with -ts
+	lineTimestampRegex = regexp.MustCompile(`\[\d{4}-\d{2}-\d{2}[ T][0-9:.]+[^\]]*\]\s*`)
+)
+
+// lineDeduper remembers emitted output lines for --dedup-output. A nil *lineDeduper lets every line through.
+type lineDeduper struct {
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+func newLineDeduper() *lineDeduper {
+	return &lineDeduper{seen: make(map[string]bool)}
+}
+
+// first reports whether line, ignoring colors and timestamps, has not been seen before, and marks it seen
+func (d *lineDeduper) first(line string) bool {
+	if d == nil {
+		return true
+	}
+	key := ansiColorRegex.ReplaceAllString(line, "")
+	key = strings.TrimSpace(lineTimestampRegex.ReplaceAllString(key, ""))
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.seen[key] {
+		return false
+	}
+	d.seen[key] = true
+	return true
+}
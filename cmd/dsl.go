@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dslStringEscaper escapes a value for a single-quoted nuclei DSL string literal
+var dslStringEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+// shellDoubleQuoteEscaper escapes the characters that stay special inside a double-quoted sh argument
+var shellDoubleQuoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "`", "\\`")
+
+// shellQuote quotes a value as one single-quoted sh word, so nothing in it is expanded or run
+func shellQuote(value string) string {
+	return `'` + strings.ReplaceAll(value, `'`, `'\''`) + `'`
+}
+
+// tcExpression builds the double-quoted nuclei -tc argument matching template names that contain any of techs,
+// escaping each tech so quotes, backslashes or shell metacharacters in a name can't break the expression
+func tcExpression(techs []string) string {
+	var conditions []string
+	for _, t := range techs {
+		conditions = append(conditions, fmt.Sprintf("contains(to_lower(name),'%s')", dslStringEscaper.Replace(strings.ToLower(t))))
+	}
+	return `"` + shellDoubleQuoteEscaper.Replace(strings.Join(conditions, " || ")) + `"`
+}
+
+// tcChunks splits techs into consecutive groups whose tcExpression is at most maxLength bytes, so a host
+// with many techs runs several nuclei processes instead of one command too long for sh or nuclei's parser.
+// A tech whose own expression is longer still gets a group of its own; maxLength 0 keeps a single group.
+func tcChunks(techs []string, maxLength int) [][]string {
+	if maxLength <= 0 || len(tcExpression(techs)) <= maxLength {
+		return [][]string{techs}
+	}
+	var chunks [][]string
+	var chunk []string
+	for _, t := range techs {
+		if len(chunk) > 0 && len(tcExpression(append(chunk[:len(chunk):len(chunk)], t))) > maxLength {
+			chunks = append(chunks, chunk)
+			chunk = nil
+		}
+		chunk = append(chunk, t)
+	}
+	return append(chunks, chunk)
+}
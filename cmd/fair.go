@@ -0,0 +1,57 @@
+package cmd
+
+// fairJob is a queued job scanning one host for one tech
+type fairJob struct {
+	host   string
+	tech   string
+	fields map[string]interface{}
+}
+
+// fairQueue holds the pending jobs of up to window hosts for --fair-hosts and hands them out round-robin, one
+// tech of each host in turn, so a host with many techs can't take every --parallel slot while the hosts after
+// it wait. A nil *fairQueue is always empty and never full.
+type fairQueue struct {
+	window int
+	hosts  [][]fairJob
+	next   int
+}
+
+// newFairQueue returns nil when window is 0 so jobs are launched in input order
+func newFairQueue(window int) *fairQueue {
+	if window <= 0 {
+		return nil
+	}
+	return &fairQueue{window: window}
+}
+
+// add queues the jobs of one host
+func (q *fairQueue) add(jobs []fairJob) {
+	if len(jobs) > 0 {
+		q.hosts = append(q.hosts, jobs)
+	}
+}
+
+// full reports whether window hosts have jobs queued, so one should be launched before reading more input
+func (q *fairQueue) full() bool {
+	return q != nil && len(q.hosts) >= q.window
+}
+
+// empty reports whether no jobs are queued
+func (q *fairQueue) empty() bool {
+	return q == nil || len(q.hosts) == 0
+}
+
+// pop takes the next job round-robin across the queued hosts; the queue must not be empty
+func (q *fairQueue) pop() fairJob {
+	if q.next >= len(q.hosts) {
+		q.next = 0
+	}
+	jobs := q.hosts[q.next]
+	if len(jobs) == 1 {
+		q.hosts = append(q.hosts[:q.next], q.hosts[q.next+1:]...)
+	} else {
+		q.hosts[q.next] = jobs[1:]
+		q.next++
+	}
+	return jobs[0]
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// extraJSONFields decodes the fields of a JSON object other than the known ones, e.g. status or title from techfinder
+func extraJSONFields(data []byte, known ...string) map[string]interface{} {
+	var all map[string]interface{}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil
+	}
+	for _, key := range known {
+		delete(all, key)
+	}
+	if len(all) == 0 {
+		return nil
+	}
+	return all
+}
+
+// UnmarshalJSON decodes host and tech and keeps the raw record, so the other fields are only decoded by
+// extraFields when --json-fields substitutes them
+func (t *TechData) UnmarshalJSON(data []byte) error {
+	type plain TechData
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	p.raw = append(json.RawMessage(nil), data...)
+	*t = TechData(p)
+	return nil
+}
+
+// extraFields decodes the fields of the record other than host and tech
+func (t TechData) extraFields() map[string]interface{} {
+	return extraJSONFields(t.raw, "host", "tech")
+}
+
+// UnmarshalJSON decodes host, tech and count and keeps the raw record, so the other fields are only decoded by
+// extraFields when --json-fields substitutes them
+func (t *HttpxTechData) UnmarshalJSON(data []byte) error {
+	type plain HttpxTechData
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	p.raw = append(json.RawMessage(nil), data...)
+	*t = HttpxTechData(p)
+	return nil
+}
+
+// extraFields decodes the fields of the record other than host, tech and count
+func (t HttpxTechData) extraFields() map[string]interface{} {
+	return extraJSONFields(t.raw, "host", "tech", "count")
+}
+
+// substituteFields replaces {field} in template with the value of each extra input field, quoted as one sh word
+// since the values come from the scanned hosts (e.g. a page title). Lists are joined with commas and objects are
+// left alone.
+func substituteFields(template string, fields map[string]interface{}) string {
+	for key, value := range fields {
+		var text string
+		switch v := value.(type) {
+		case nil:
+			text = ""
+		case string:
+			text = v
+		case float64, bool:
+			text = fmt.Sprint(v)
+		case []interface{}:
+			var items []string
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			text = strings.Join(items, ",")
+		default:
+			continue
+		}
+		template = strings.Replace(template, "{"+key+"}", shellQuote(text), -1)
+	}
+	return template
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// placeholderFile starts a {file:<glob>} placeholder, replaced with the single-quoted path of the first file matching
+// the glob after the {host} and {tech} inside it are filled in, e.g. {file:configs/{host}.yaml}
+const placeholderFile = "{file:"
+
+// resolveFilePlaceholders substitutes every {file:<glob>} of template for a job on host and tech. When a glob
+// matches no file it returns that glob and false, and the job should be skipped.
+func resolveFilePlaceholders(template, host, tech string) (string, string, bool) {
+	from := 0
+	for {
+		start := strings.Index(template[from:], placeholderFile)
+		if start < 0 {
+			return template, "", true
+		}
+		start += from
+
+		// Find the brace closing the placeholder, skipping the ones of {host} and {tech} inside it
+		end, depth := -1, 1
+		for i := start + len(placeholderFile); i < len(template) && end < 0; i++ {
+			switch template[i] {
+			case '{':
+				depth++
+			case '}':
+				if depth--; depth == 0 {
+					end = i
+				}
+			}
+		}
+		if end < 0 {
+			return template, "", true
+		}
+
+		pattern := template[start+len(placeholderFile) : end]
+		pattern = strings.Replace(pattern, placeholderHost, host, -1)
+		pattern = strings.Replace(pattern, placeholderTech, tech, -1)
+		matches, err := filepath.Glob(pattern)
+		if err != nil || len(matches) == 0 {
+			return template, pattern, false
+		}
+
+		// The path holds the host, so it is inserted as one quoted word like {host} itself
+		path := shellQuote(matches[0])
+		template = template[:start] + path + template[end+1:]
+		from = start + len(path)
+	}
+}
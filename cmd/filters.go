@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// techFilters holds the parsed tech filter flags so their combination can be validated in one place
+type techFilters struct {
+	include    []string
+	exclude    []string
+	requireAll []string
+	versions   []versionConstraint
+	prefix     bool // --tech-prefix-match
+}
+
+// validate reports the first combination of filter flags that conflicts or can never match a tech.
+// Filters apply in this order: --require-all-tech selects hosts, --tech-version-filter, --include-tech and
+// then --exclude-tech select the techs scanned on them, so an exclude entry can remove members of an included family.
+// Entries are compared the way matchesTechList matches techs, globs and --tech-prefix-match included.
+func (f techFilters) validate() error {
+	for _, tech := range nonEmpty(f.include) {
+		// A pattern or prefix entry names a family, which an exclude entry may narrow but not empty as a whole
+		family := strings.ContainsAny(tech, "*?[") || f.prefix
+		if contains(f.exclude, tech) || (!family && matchesTechList(f.exclude, tech, f.prefix)) {
+			return fmt.Errorf("--include-tech %s is excluded by --exclude-tech, so it would never be scanned", tech)
+		}
+	}
+
+	for _, tech := range nonEmpty(f.requireAll) {
+		if matchesTechList(f.exclude, tech, f.prefix) {
+			return fmt.Errorf("--require-all-tech %s is excluded by --exclude-tech, so hosts would be selected for a tech that is never scanned", tech)
+		}
+	}
+
+	for _, c := range f.versions {
+		if matchesTechList(f.exclude, c.tech, f.prefix) {
+			return fmt.Errorf("--tech-version-filter %s%s%s targets a tech excluded by --exclude-tech", c.tech, c.op, c.version)
+		}
+		if include := nonEmpty(f.include); len(include) > 0 && !matchesTechList(include, c.tech, f.prefix) {
+			return fmt.Errorf("--tech-version-filter %s%s%s targets a tech missing from --include-tech, so it would never be scanned", c.tech, c.op, c.version)
+		}
+	}
+	if len(f.versions) > 0 {
+		for _, tech := range nonEmpty(f.include) {
+			constrained := false
+			for _, c := range f.versions {
+				if matchesTechList([]string{tech}, c.tech, f.prefix) {
+					constrained = true
+					break
+				}
+			}
+			if !constrained {
+				return fmt.Errorf("--include-tech %s has no --tech-version-filter constraint, and techs without one are never scanned", tech)
+			}
+		}
+	}
+	return nil
+}
+
+// scans reports whether a tech, by its name after techCase and its version, passes --tech-version-filter,
+// --include-tech and --exclude-tech (which --exclude-noise was merged into), so it would be scanned
+func (f techFilters) scans(name, version string) bool {
+	if len(f.versions) > 0 && !techVersionAllowed(f.versions, name, version) {
+		return false
+	}
+	if include := nonEmpty(f.include); len(include) > 0 && !matchesTechList(include, name, f.prefix) {
+		return false
+	}
+	return !matchesTechList(f.exclude, name, f.prefix)
+}
+
+// techCase lowercases a tech name, unless --case-sensitive keeps names exactly as the input has them
+func techCase(name string, caseSensitive bool) string {
+	if caseSensitive {
+		return name
+	}
+	return strings.ToLower(name)
+}
+
+// matchesTechList reports whether tech is in list, or with prefix (--tech-prefix-match) whether it starts with
+// one of the entries, so "wordpress" also matches "wordpress-plugin-x". Entries with glob characters such as
+// "wp-*" match as patterns either way.
+func matchesTechList(list []string, tech string, prefix bool) bool {
+	for _, entry := range list {
+		if entry == "" {
+			continue
+		}
+		if strings.ContainsAny(entry, "*?[") {
+			if matched, _ := path.Match(entry, tech); matched {
+				return true
+			}
+		} else if entry == tech || (prefix && strings.HasPrefix(tech, entry)) {
+			return true
+		}
+	}
+	return false
+}
+
+// nonEmpty returns the entries of list that aren't blank, e.g. from a trailing comma in a flag
+func nonEmpty(list []string) []string {
+	var entries []string
+	for _, entry := range list {
+		if strings.TrimSpace(entry) != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
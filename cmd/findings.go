@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+)
+
+// Formats of --parse-findings
+var findingFormats = []string{"json", "csv"}
+
+// Columns of the --parse-findings csv output, in the order of the Finding fields
+var findingCSVHeader = []string{"template-id", "matcher", "protocol", "severity", "host", "matched-url", "extracted", "tech"}
+
+// Finding is a nuclei finding line split into its fields for --parse-findings, e.g.
+// "[tomcat-detect:version] [http] [info] https://a.com/ ["9.0.1"]"
+type Finding struct {
+	TemplateID string   `json:"template-id"`
+	Matcher    string   `json:"matcher,omitempty"`
+	Protocol   string   `json:"protocol"`
+	Severity   string   `json:"severity"`
+	Host       string   `json:"host"`
+	MatchedURL string   `json:"matched-url"`
+	Extracted  []string `json:"extracted,omitempty"`
+	Tech       string   `json:"tech"`
+}
+
+// parseFinding splits a finding line of the job scanning host for tech. Colors and -ts timestamps are ignored,
+// so lines printed with or without -silent parse the same; fields missing from the line are left empty.
+func parseFinding(line, host, tech string) Finding {
+	line = ansiColorRegex.ReplaceAllString(line, "")
+	line = strings.TrimSpace(lineTimestampRegex.ReplaceAllString(line, ""))
+	finding := Finding{Host: host, Tech: tech}
+
+	// The template-id[:matcher], protocol and severity come first, in brackets
+	var brackets []string
+	for len(brackets) < 3 && strings.HasPrefix(line, "[") {
+		end := strings.Index(line, "]")
+		if end < 0 {
+			break
+		}
+		brackets = append(brackets, line[1:end])
+		line = strings.TrimSpace(line[end+1:])
+	}
+	if len(brackets) > 0 {
+		parts := strings.SplitN(brackets[0], ":", 2)
+		finding.TemplateID = parts[0]
+		if len(parts) == 2 {
+			finding.Matcher = parts[1]
+		}
+	}
+	if len(brackets) > 1 {
+		finding.Protocol = brackets[1]
+	}
+	if len(brackets) > 2 {
+		finding.Severity = strings.ToLower(brackets[2])
+	}
+
+	// Then the matched URL and any extracted results, e.g. ["9.0.1","x"] or [9.0.1]
+	fields := strings.Fields(line)
+	if len(fields) > 0 {
+		finding.MatchedURL = fields[0]
+	}
+	for _, field := range fields[min(1, len(fields)):] {
+		for _, value := range strings.Split(strings.Trim(field, "[]"), ",") {
+			if value = strings.Trim(value, `"`); value != "" {
+				finding.Extracted = append(finding.Extracted, value)
+			}
+		}
+	}
+	return finding
+}
+
+// formatFinding renders a finding as a JSON line or a CSV record, ending in a newline
+func formatFinding(finding Finding, format string) string {
+	if format == "csv" {
+		var buf bytes.Buffer
+		writer := csv.NewWriter(&buf)
+		writer.Write([]string{finding.TemplateID, finding.Matcher, finding.Protocol, finding.Severity, finding.Host,
+			finding.MatchedURL, strings.Join(finding.Extracted, ";"), finding.Tech})
+		writer.Flush()
+		return buf.String()
+	}
+	data, _ := json.Marshal(finding)
+	return string(data) + "\n"
+}
+
+// formatFindingCSVHeader returns the header line of the --parse-findings csv output
+func formatFindingCSVHeader() string {
+	return strings.Join(findingCSVHeader, ",") + "\n"
+}
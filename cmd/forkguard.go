@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Environment variable counting how deeply vulntechfinder runs are nested inside each other's jobs
+const nestingEnv = "VULNTECHFINDER_DEPTH"
+
+// A run nested this deep inside other runs' jobs is refused, as it most likely comes from a recursive template
+const maxNestingDepth = 2
+
+// Shell function fork bomb such as ":(){ :|:& };:"
+var forkBombRegex = regexp.MustCompile(`[^\s(){}]+\s*\(\)\s*\{[^}]*\|[^}]*&`)
+
+// nestingDepth returns how many vulntechfinder jobs this run is nested in
+func nestingDepth() int {
+	depth, _ := strconv.Atoi(os.Getenv(nestingEnv))
+	return depth
+}
+
+// checkNesting refuses a run nested too deeply in other runs' jobs and returns the variable for the children's environment
+func checkNesting() (string, error) {
+	depth := nestingDepth()
+	if depth >= maxNestingDepth {
+		return "", fmt.Errorf("vulntechfinder is nested %d levels deep inside its own jobs, the command template is probably recursive", depth)
+	}
+	return nestingEnv + "=" + strconv.Itoa(depth+1), nil
+}
+
+// recursionWarnings lists the reasons a command template looks like it could spawn processes without bound
+func recursionWarnings(template string) []string {
+	var warnings []string
+	names := []string{"vulntechfinder"}
+	if self := filepath.Base(os.Args[0]); self != "" && self != "vulntechfinder" {
+		names = append(names, self)
+	}
+	for _, name := range names {
+		if regexp.MustCompile(`(^|[\s/;|&(])` + regexp.QuoteMeta(name) + `($|\s)`).MatchString(template) {
+			warnings = append(warnings, fmt.Sprintf("the command runs %s itself", name))
+		}
+	}
+	if forkBombRegex.MatchString(template) {
+		warnings = append(warnings, "the command defines a shell function that pipes into itself in the background (fork bomb)")
+	}
+	if strings.Contains(template, "$0") {
+		warnings = append(warnings, "the command references $0 and may re-invoke its own shell")
+	}
+	return warnings
+}
+
+// procBudget caps the total number of commands started in a run with --max-procs (0 for no cap)
+type procBudget struct {
+	max      int64
+	started  atomic.Int64
+	exceeded sync.Once
+}
+
+// take reserves one command start, reporting the first time the budget runs out
+func (b *procBudget) take() bool {
+	if b.max <= 0 {
+		return true
+	}
+	if b.started.Add(1) <= b.max {
+		return true
+	}
+	b.exceeded.Do(func() {
+		fmt.Printf("Reached --max-procs of %d commands, not starting any more\n", b.max)
+	})
+	return false
+}
+
+// exhausted reports whether no more commands may be started
+func (b *procBudget) exhausted() bool {
+	return b.max > 0 && b.started.Load() >= b.max
+}
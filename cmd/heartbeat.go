@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Number of jobs listed in the slowest jobs summary
+const slowestJobsShown = 10
+
+// jobTiming is how long a finished host/tech job took
+type jobTiming struct {
+	key      string
+	duration time.Duration
+}
+
+// runningJob is a started job, under the host/tech key it is reported as
+type runningJob struct {
+	key     string
+	started time.Time
+}
+
+// activeJobs tracks the host/tech jobs currently running so a stalled scan can report what it is stuck on,
+// and with keepTimings the duration of finished jobs for --timings. Jobs are tracked by an id from start, as
+// two running jobs can share a key (e.g. batches of the same size and tech).
+type activeJobs struct {
+	mu          sync.Mutex
+	started     time.Time
+	nextID      int
+	running     map[int]runningJob
+	lastDone    time.Time
+	done        int // finished jobs
+	keepTimings bool
+	finished    []jobTiming
+}
+
+func newActiveJobs(keepTimings bool) *activeJobs {
+	return &activeJobs{
+		started:     time.Now(),
+		running:     make(map[int]runningJob),
+		lastDone:    time.Now(),
+		keepTimings: keepTimings,
+	}
+}
+
+// elapsed returns how long the scan has been running
+func (a *activeJobs) elapsed() time.Duration {
+	return time.Since(a.started)
+}
+
+// start marks a job as running and returns the id to pass to finish
+func (a *activeJobs) start(key string) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.nextID++
+	a.running[a.nextID] = runningJob{key, time.Now()}
+	return a.nextID
+}
+
+// finish removes the job started with id from the running set, records the completion time and returns how
+// long the job ran
+func (a *activeJobs) finish(id int) time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.lastDone = time.Now()
+	job := a.running[id]
+	duration := a.lastDone.Sub(job.started)
+	delete(a.running, id)
+	a.done++
+	if a.keepTimings {
+		a.finished = append(a.finished, jobTiming{key: job.key, duration: duration})
+	}
+	return duration
+}
+
+// slowest returns up to n finished jobs, slowest first
+func (a *activeJobs) slowest(n int) []jobTiming {
+	a.mu.Lock()
+	timings := append([]jobTiming(nil), a.finished...)
+	a.mu.Unlock()
+
+	sort.Slice(timings, func(i, j int) bool { return timings[i].duration > timings[j].duration })
+	if len(timings) > n {
+		timings = timings[:n]
+	}
+	return timings
+}
+
+// printSlowest prints the slowest finished jobs
+func (a *activeJobs) printSlowest() {
+	timings := a.slowest(slowestJobsShown)
+	if len(timings) == 0 {
+		return
+	}
+	fmt.Printf("Slowest %d jobs:\n", len(timings))
+	for _, t := range timings {
+		fmt.Printf("  %-12s %s\n", t.duration.Round(time.Millisecond), t.key)
+	}
+}
+
+// dumpStats writes the progress of the scan to w: elapsed time, running jobs (longest running first), finished
+// jobs and the slowest of them, plus the extra line from the command when it has one
+func (a *activeJobs) dumpStats(w io.Writer, extra func() string) {
+	a.mu.Lock()
+	elapsed := time.Since(a.started)
+	var running []runningJob
+	for _, job := range a.running {
+		running = append(running, job)
+	}
+	finished := a.done
+	a.mu.Unlock()
+
+	sort.Slice(running, func(i, j int) bool { return running[i].started.Before(running[j].started) })
+	fmt.Fprintf(w, "Stats after %s: %d jobs running, %d finished\n", elapsed.Round(time.Second), len(running), finished)
+	if extra != nil {
+		if line := extra(); line != "" {
+			fmt.Fprintln(w, line)
+		}
+	}
+	for _, job := range running {
+		fmt.Fprintf(w, "  running %-12s %s\n", time.Since(job.started).Round(time.Second), job.key)
+	}
+	for _, t := range a.slowest(slowestJobsShown) {
+		fmt.Fprintf(w, "  took    %-12s %s\n", t.duration.Round(time.Millisecond), t.key)
+	}
+}
+
+// watch prints a warning with the running jobs whenever no job has completed within interval, until stop is closed
+func (a *activeJobs) watch(interval time.Duration, stop <-chan struct{}) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			a.mu.Lock()
+			idle := time.Since(a.lastDone)
+			var jobs []string
+			for _, job := range a.running {
+				jobs = append(jobs, fmt.Sprintf("%s (running %s)", job.key, time.Since(job.started).Round(time.Second)))
+			}
+			a.mu.Unlock()
+
+			if idle < interval || len(jobs) == 0 {
+				continue
+			}
+
+			sort.Strings(jobs)
+			fmt.Printf("WARNING: no job completed in the last %s, %d still running:\n  %s\n", idle.Round(time.Second), len(jobs), strings.Join(jobs, "\n  "))
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// hookCommand substitutes {host} and {tech} in a --pre-cmd/--post-cmd template, the host single-quoted
+func hookCommand(template, host, tech string) string {
+	return strings.NewReplacer(placeholderHost, shellQuote(host), placeholderTech, tech).Replace(template)
+}
+
+// runHook runs a --pre-cmd/--post-cmd command in dir with its output passed through to the terminal
+func runHook(cmdStr, dir string, env []string) error {
+	cmd := exec.Command("sh", "-c", cmdStr)
+	cmd.Dir = dir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	return cmd.Run()
+}
+
+// prefixCommand runs a resolved command under the --command-prefix wrapper, e.g. "proxychains -q" or "nice -n 10".
+// The command is handed to the wrapper as one "sh -c" argument, so assignments, pipelines and && chains all run
+// inside the wrapper instead of only their first command.
+func prefixCommand(prefix, cmdStr string) string {
+	if prefix = strings.TrimSpace(prefix); prefix == "" {
+		return cmdStr
+	}
+	return prefix + " sh -c " + shellQuote(cmdStr)
+}
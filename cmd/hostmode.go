@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Supported values for --host-mode: how the host reaches the child command
+var hostModes = []string{"stdin", "arg", "both"}
+
+// validateHostMode checks the --host-mode value and that arg modes have a {host} placeholder to fill
+func validateHostMode(mode, template string) error {
+	if !contains(hostModes, mode) {
+		return fmt.Errorf("invalid --host-mode %q (expected one of: %s)", mode, strings.Join(hostModes, ", "))
+	}
+	if mode != "stdin" && !strings.Contains(template, placeholderHost) {
+		return fmt.Errorf("--host-mode %s requires a {host} placeholder in the command template", mode)
+	}
+	return nil
+}
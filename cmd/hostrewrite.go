@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// hostRewrite is a --host-rewrite rule replacing matches of pattern in each host
+type hostRewrite struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// parseHostRewrites compiles "pattern=>replacement" rules
+func parseHostRewrites(rules []string) ([]hostRewrite, error) {
+	var rewrites []hostRewrite
+	for _, rule := range rules {
+		parts := strings.SplitN(rule, "=>", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid rule %q (expected pattern=>replacement)", rule)
+		}
+		re, err := regexp.Compile(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern in rule %q: %s", rule, err)
+		}
+		rewrites = append(rewrites, hostRewrite{pattern: re, replacement: parts[1]})
+	}
+	return rewrites, nil
+}
+
+// rewriteHost applies the rules to host in order
+func rewriteHost(host string, rewrites []hostRewrite) string {
+	for _, r := range rewrites {
+		host = r.pattern.ReplaceAllString(host, r.replacement)
+	}
+	return host
+}
+
+// normalizeHost canonicalizes a host for --normalize-host so logically identical hosts collapse to one job:
+// lowercase, path dropped, trailing dot removed and the default port of the scheme (80/443 without one) removed
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+
+	scheme := ""
+	if i := strings.Index(host, "://"); i >= 0 {
+		scheme, host = host[:i], host[i+3:]
+	}
+	if i := strings.IndexAny(host, "/?#"); i >= 0 {
+		host = host[:i]
+	}
+
+	name, port := host, ""
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		name, port = h, p
+		if strings.Contains(name, ":") {
+			name = "[" + name + "]"
+		}
+	}
+	name = strings.TrimSuffix(name, ".")
+
+	switch {
+	case port == "":
+	case scheme == "http" && port == "80", scheme == "https" && port == "443":
+		port = ""
+	case scheme == "" && (port == "80" || port == "443"):
+		port = ""
+	}
+
+	if port != "" {
+		name += ":" + port
+	}
+	if scheme != "" {
+		return scheme + "://" + name
+	}
+	return name
+}
@@ -2,24 +2,28 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/semaphore"
 )
 
 // Structure to map the JSON data
 type HttpxTechData struct {
-	Host  string   `json:"host"`
-	Tech  []string `json:"tech"`
-	Count int      `json:"count,omitempty"`
+	Host  string          `json:"host"`
+	Tech  []string        `json:"tech"`
+	Count int             `json:"count,omitempty"`
+	raw   json.RawMessage // the whole input record, for the other fields substituted by --json-fields
 }
 
 // httpxCmd represents the httpx command
@@ -36,39 +40,380 @@ Examples:
   cat techfinder-output.json | vulntechfinder httpx --cmd "httpx -duc -silent -path {tech}" --parallel 10 --output httpx-output.txt
 `,
 	Run: func(cmd *cobra.Command, args []string) {
-		httpxCmdStr, _ := cmd.Flags().GetString("cmd")
+		httpxCmds, _ := cmd.Flags().GetStringArray("cmd")
 		verbose, _ := cmd.Flags().GetBool("verbose")
 		process, _ := cmd.Flags().GetBool("process")
 		parallel, _ := cmd.Flags().GetInt("parallel")
 		Output, _ := cmd.Flags().GetString("output")
 		excludeTech, _ := cmd.Flags().GetString("exclude-tech")
 		includeTech, _ := cmd.Flags().GetString("include-tech")
+		idleWarn, _ := cmd.Flags().GetDuration("idle-warn")
+		extraArgs, _ := cmd.Flags().GetString("extra-args")
+		hostMode, _ := cmd.Flags().GetString("host-mode")
+		limit, _ := cmd.Flags().GetInt("limit")
+		insecure, _ := cmd.Flags().GetBool("insecure")
+		timings, _ := cmd.Flags().GetBool("timings")
+		hostRewriteRules, _ := cmd.Flags().GetStringArray("host-rewrite")
+		resumeFile, _ := cmd.Flags().GetString("resume")
+		resumeGranularity, _ := cmd.Flags().GetString("resume-granularity")
+		seenDBFile, _ := cmd.Flags().GetString("seen-db")
+		skipSeenWithin, _ := cmd.Flags().GetDuration("skip-seen-within")
+		globalTimeout, _ := cmd.Flags().GetDuration("timeout")
+		maxJobLines, _ := cmd.Flags().GetInt("max-output-lines-per-job")
+		nice, _ := cmd.Flags().GetInt("nice")
+		maxMemoryStr, _ := cmd.Flags().GetString("max-memory")
+		maxCPUTime, _ := cmd.Flags().GetDuration("max-cpu-time")
+		timeoutMapStr, _ := cmd.Flags().GetString("timeout-map")
+		jsonFields, _ := cmd.Flags().GetBool("json-fields")
+		sequential, _ := cmd.Flags().GetBool("sequential")
+		envFile, _ := cmd.Flags().GetString("env-file")
+		concurrencyAuto, _ := cmd.Flags().GetBool("concurrency-auto")
+		dedupOutput, _ := cmd.Flags().GetBool("dedup-output")
+		inputFormat, _ := cmd.Flags().GetString("input-format")
+		quietOutput, _ := cmd.Flags().GetBool("quiet-output")
+		stdoutFormat, _ := cmd.Flags().GetString("stdout-format")
+		fairHosts, _ := cmd.Flags().GetInt("fair-hosts")
+		outputMaxSizeStr, _ := cmd.Flags().GetString("output-max-size")
+		preCmd, _ := cmd.Flags().GetString("pre-cmd")
+		commandPrefix, _ := cmd.Flags().GetString("command-prefix")
+		postCmd, _ := cmd.Flags().GetString("post-cmd")
+		skipOnPreCmdFail, _ := cmd.Flags().GetBool("skip-on-pre-cmd-fail")
+		versionFilters, _ := cmd.Flags().GetStringArray("tech-version-filter")
+		workdir, _ := cmd.Flags().GetString("workdir")
+		hostComment, _ := cmd.Flags().GetBool("output-append-host-comment")
+		maxRuntime, _ := cmd.Flags().GetDuration("max-runtime")
+		requireAllTech, _ := cmd.Flags().GetString("require-all-tech")
+		jsonPretty, _ := cmd.Flags().GetBool("output-json-pretty")
+		validateOutput, _ := cmd.Flags().GetBool("validate-output")
+		randomUA, _ := cmd.Flags().GetBool("random-ua")
+		uaFile, _ := cmd.Flags().GetString("ua-file")
+		cmdFile, _ := cmd.Flags().GetString("cmd-file")
+		normalizeHosts, _ := cmd.Flags().GetBool("normalize-host")
+		techWeightStr, _ := cmd.Flags().GetString("tech-weight")
+		samplePerTech, _ := cmd.Flags().GetInt("sample-per-tech")
+		fastDecode, _ := cmd.Flags().GetBool("fast-decode")
+		techMapOutput, _ := cmd.Flags().GetString("tech-map-output")
+		includeCommand, _ := cmd.Flags().GetBool("output-include-command")
+		techPrefixMatch, _ := cmd.Flags().GetBool("tech-prefix-match")
+		templateVarFlags, _ := cmd.Flags().GetStringArray("var")
+		maxProcs, _ := cmd.Flags().GetInt("max-procs")
+		printPlan, _ := cmd.Flags().GetBool("print-plan")
+		inputPath, _ := cmd.Flags().GetString("input")
+		validateOnly, _ := cmd.Flags().GetBool("validate-only")
+		abortOnFirstError, _ := cmd.Flags().GetBool("abort-on-first-error")
+		excludeNoise, _ := cmd.Flags().GetBool("exclude-noise")
+		shutdownGrace, _ := cmd.Flags().GetDuration("shutdown-grace")
+		expandCIDR, _ := cmd.Flags().GetBool("expand-cidr")
+		expandCIDRMax, _ := cmd.Flags().GetInt("expand-cidr-max")
+		orderedOutputFlag, _ := cmd.Flags().GetBool("ordered-output")
+		orderedBufferStr, _ := cmd.Flags().GetString("ordered-buffer")
+		pathMapFile, _ := cmd.Flags().GetString("path-map")
+		strictJSON, _ := cmd.Flags().GetBool("strict-json")
+		batchSize, _ := cmd.Flags().GetInt("batch-size")
+		symlinkLatest, _ := cmd.Flags().GetBool("output-symlink-latest")
+		onlyNonempty, _ := cmd.Flags().GetBool("only-nonempty")
+		silent, _ := cmd.Flags().GetBool("silent")
+		summaryJSON, _ := cmd.Flags().GetBool("summary-json")
+		outputDaily, _ := cmd.Flags().GetBool("output-daily")
+		refingerprintNull, _ := cmd.Flags().GetBool("refingerprint-null")
+		continueOnTechfinderError, _ := cmd.Flags().GetBool("continue-on-techfinder-error")
+		techSegment, _ := cmd.Flags().GetString("tech-segment")
+		caseSensitive, _ := cmd.Flags().GetBool("case-sensitive")
+		unsafeTechPolicy, _ := cmd.Flags().GetString("unsafe-tech-policy")
+		unsafeTechChars, _ := cmd.Flags().GetString("unsafe-tech-chars")
+		errorThreshold, _ := cmd.Flags().GetFloat64("error-threshold")
+		errorBackoff, _ := cmd.Flags().GetDuration("error-backoff")
+		sampleRandom, _ := cmd.Flags().GetBool("sample-random")
+		jsonHostKey, _ := cmd.Flags().GetString("json-host-key")
+		jsonTechKey, _ := cmd.Flags().GetString("json-tech-key")
+		rareFirst, _ := cmd.Flags().GetBool("rare-first")
+		csvHostColumn, _ := cmd.Flags().GetInt("csv-host-column")
+		csvTechColumn, _ := cmd.Flags().GetInt("csv-tech-column")
+		csvTechSeparator, _ := cmd.Flags().GetString("csv-tech-separator")
+		insecureFlagsMap, _ := cmd.Flags().GetString("insecure-flags")
 
-		if httpxCmdStr == "" {
-			fmt.Println("Usage: vulntechfinder httpx --cmd <httpx command> [--parallel N] [--output file]")
+		if cmdFile != "" {
+			if len(httpxCmds) > 0 {
+				fmt.Println("Error: --cmd and --cmd-file can't be used together")
+				os.Exit(1)
+			}
+			fileCmd, err := readCmdFile(cmdFile)
+			if err != nil {
+				fmt.Printf("Error reading --cmd-file: %s\n", err)
+				os.Exit(1)
+			}
+			httpxCmds = []string{fileCmd}
+		}
+
+		if len(httpxCmds) == 0 || contains(httpxCmds, "") {
+			fmt.Println("Usage: vulntechfinder httpx --cmd <httpx command> | --cmd-file <file> [--parallel N] [--output file]")
 			os.Exit(1)
 		}
 
+		// Fill the per-run {name} placeholders given with --var
+		templateVars, err := parseTemplateVars(templateVarFlags)
+		if err != nil {
+			fmt.Printf("Error: %s\n", err)
+			os.Exit(1)
+		}
+		for i := range httpxCmds {
+			httpxCmds[i] = applyTemplateVars(httpxCmds[i], templateVars)
+		}
+
+		// Refuse runs nested in their own jobs and warn about templates that look like they recurse
+		depthEnv, err := checkNesting()
+		if err != nil {
+			fmt.Printf("Error: %s\n", err)
+			os.Exit(1)
+		}
+		for _, httpxCmdStr := range httpxCmds {
+			for _, warning := range recursionWarnings(httpxCmdStr) {
+				fmt.Printf("WARNING: %s; consider capping it with --max-procs\n", warning)
+			}
+		}
+		procs := &procBudget{max: int64(maxProcs)}
+
 		if parallel <= 0 {
 			parallel = 50
 		}
 
+		// Size the pool from the CPU count unless --parallel was given explicitly
+		if concurrencyAuto && !cmd.Flags().Changed("parallel") {
+			parallel = autoConcurrency()
+			fmt.Printf("Using %d parallel processes (--concurrency-auto)\n", parallel)
+		}
+
+		// Run one job at a time in input order so output is reproducible
+		if sequential {
+			parallel = 1
+		}
+
+		// Append --extra-args to the template so the -path detection and {tech} replacement also see them
+		if extraArgs = strings.TrimSpace(extraArgs); extraArgs != "" {
+			for i := range httpxCmds {
+				httpxCmds[i] = httpxCmds[i] + " " + extraArgs
+			}
+		}
+
+		// Append the tool's skip-verify flag for --insecure
+		if insecure {
+			overrides, err := parseKeyValueList(insecureFlagsMap, false)
+			if err != nil {
+				fmt.Printf("Error parsing --insecure-flags: %s\n", err)
+				os.Exit(1)
+			}
+			for i := range httpxCmds {
+				flag, ok := insecureFlagFor(httpxCmds[i], "httpx", overrides)
+				if !ok {
+					fmt.Printf("Error: --insecure has no skip-verify flag for --cmd %q (nuclei and httpx skip certificate checks by default); set one with --insecure-flags tool=flag\n", httpxCmds[i])
+					os.Exit(1)
+				}
+				httpxCmds[i] = httpxCmds[i] + " " + flag
+			}
+		}
+
+		for _, httpxCmdStr := range httpxCmds {
+			if err := validateHostMode(hostMode, httpxCmdStr); err != nil {
+				fmt.Printf("Error: %s\n", err)
+				os.Exit(1)
+			}
+		}
+
+		// All templates of a repeated --cmd, for the checks of features that apply when any of them uses one
+		httpxCmdStr := strings.Join(httpxCmds, " ; ")
+
+		if strings.Contains(httpxCmdStr, placeholderFile) && batchSize > 0 {
+			fmt.Println("Error: {file:<glob>} is resolved per host and can't be used with --batch-size")
+			os.Exit(1)
+		}
+
+		if fairHosts < 0 || (fairHosts > 0 && batchSize > 0) {
+			fmt.Println("Error: --fair-hosts must be a positive number of hosts and can't be used with --batch-size")
+			os.Exit(1)
+		}
+
+		if batchSize > 0 && hostMode != "stdin" {
+			fmt.Println("Error: --batch-size feeds a batch of hosts on stdin and requires --host-mode stdin")
+			os.Exit(1)
+		}
+
 		// Parse exclude and include lists (support both comma-separated and file paths)
-		excludeList, err := HttpxparseTechInput(excludeTech)
+		excludeList, err := parseTechInput(excludeTech, caseSensitive)
 		if err != nil {
 			fmt.Printf("Error reading exclude-tech input: %s\n", err)
 			os.Exit(1)
 		}
 
-		includeList, err := HttpxparseTechInput(includeTech)
+		includeList, err := parseTechInput(includeTech, caseSensitive)
 		if err != nil {
 			fmt.Printf("Error reading include-tech input: %s\n", err)
 			os.Exit(1)
 		}
 
-		// Validate that both exclude and include are not used together
-		if len(excludeList) > 0 && len(includeList) > 0 {
-			fmt.Println("Error: Cannot use both --exclude-tech and --include-tech flags together")
+		// Merge the built-in --exclude-noise list into the exclude list, except for techs included explicitly
+		if excludeNoise {
+			excludeList = append(excludeList, noiseExcludes(includeList)...)
+		}
+
+		requiredTechs, err := parseTechInput(requireAllTech, caseSensitive)
+		if err != nil {
+			fmt.Printf("Error reading require-all-tech input: %s\n", err)
+			os.Exit(1)
+		}
+
+		hostRewrites, err := parseHostRewrites(hostRewriteRules)
+		if err != nil {
+			fmt.Printf("Error parsing --host-rewrite: %s\n", err)
+			os.Exit(1)
+		}
+
+		versionConstraints, err := parseVersionConstraints(versionFilters)
+		if err != nil {
+			fmt.Printf("Error parsing --tech-version-filter: %s\n", err)
+			os.Exit(1)
+		}
+
+		timeoutMap, err := parseTimeoutMap(timeoutMapStr, caseSensitive)
+		if err != nil {
+			fmt.Printf("Error parsing --timeout-map: %s\n", err)
+			os.Exit(1)
+		}
+
+		techAliases, err := parseTechMap(techMapOutput)
+		if err != nil {
+			fmt.Printf("Error reading --tech-map-output: %s\n", err)
+			os.Exit(1)
+		}
+
+		wordlistPaths, err := parsePathMap(pathMapFile, caseSensitive)
+		if err != nil {
+			fmt.Printf("Error reading --path-map: %s\n", err)
+			os.Exit(1)
+		}
+
+		techWeights, err := parseTechWeights(techWeightStr, caseSensitive)
+		if err != nil {
+			fmt.Printf("Error parsing --tech-weight: %s\n", err)
+			os.Exit(1)
+		}
+
+		childEnv, err := loadEnvFile(envFile)
+		if err != nil {
+			fmt.Printf("Error reading --env-file: %s\n", err)
+			os.Exit(1)
+		}
+		childEnv = append(childEnv, depthEnv)
+
+		// --ua-file implies --random-ua
+		var userAgents []string
+		if randomUA || uaFile != "" {
+			userAgents, err = loadUserAgents(uaFile)
+			if err != nil {
+				fmt.Printf("Error reading --ua-file: %s\n", err)
+				os.Exit(1)
+			}
+		}
+
+		if quietOutput && Output == "" {
+			fmt.Println("Error: --quiet-output suppresses terminal output and needs --output")
+			os.Exit(1)
+		}
+
+		outputMaxSize, err := parseSize(outputMaxSizeStr)
+		if err != nil {
+			fmt.Printf("Error parsing --output-max-size: %s\n", err)
+			os.Exit(1)
+		}
+
+		// De-prioritize and cap the scan commands with --nice, --max-memory and --max-cpu-time
+		maxMemory, err := parseSize(maxMemoryStr)
+		if err != nil {
+			fmt.Printf("Error parsing --max-memory: %s\n", err)
+			os.Exit(1)
+		}
+		limits, err := newChildLimits(nice, maxMemory, maxCPUTime)
+		if err != nil {
+			fmt.Printf("Error: %s\n", err)
+			os.Exit(1)
+		}
+
+		orderedBuffer, err := parseSize(orderedBufferStr)
+		if err != nil {
+			fmt.Printf("Error parsing --ordered-buffer: %s\n", err)
+			os.Exit(1)
+		}
+
+		if workdir != "" {
+			if info, err := os.Stat(workdir); err != nil || !info.IsDir() {
+				fmt.Printf("Error: --workdir %q is not a directory\n", workdir)
+				os.Exit(1)
+			}
+		}
+
+		if outputDaily && Output == "" {
+			fmt.Println("Error: --output-daily needs --output")
+			os.Exit(1)
+		}
+
+		if onlyNonempty && includeCommand {
+			fmt.Println("Error: --output-include-command always writes the output file, so it can't be used with --only-nonempty")
+			os.Exit(1)
+		}
+
+		if validateOutput && (Output == "" || !jsonPretty || hostComment || compressedOutput(Output)) {
+			fmt.Println("Error: --validate-output checks JSON output and needs --output with --output-json-pretty, without --output-append-host-comment or a compressed .zst path")
+			os.Exit(1)
+		}
+
+		if symlinkLatest && !strings.Contains(Output, outputTimestamp) {
+			fmt.Println("Error: --output-symlink-latest needs an --output path with {timestamp}, e.g. results/scan-{timestamp}.txt")
+			os.Exit(1)
+		}
+
+		if jsonPretty && (Output == "" || hostComment) {
+			fmt.Println("Error: --output-json-pretty needs --output and can't be combined with --output-append-host-comment")
+			os.Exit(1)
+		}
+
+		if strictJSON && fastDecode {
+			fmt.Println("Error: --strict-json and --fast-decode can't be used together")
+			os.Exit(1)
+		}
+
+		if sampleRandom && samplePerTech <= 0 {
+			fmt.Println("Error: --sample-random picks the --sample-per-tech hosts and needs --sample-per-tech")
+			os.Exit(1)
+		}
+
+		if !contains(inputFormats, inputFormat) {
+			fmt.Printf("Error: invalid --input-format %q (expected one of: %s)\n", inputFormat, strings.Join(inputFormats, ", "))
+			os.Exit(1)
+		}
+
+		if !contains(stdoutFormats, stdoutFormat) {
+			fmt.Printf("Error: invalid --stdout-format %q (expected one of: %s)\n", stdoutFormat, strings.Join(stdoutFormats, ", "))
+			os.Exit(1)
+		}
+
+		if !contains(resumeGranularities, resumeGranularity) {
+			fmt.Printf("Error: invalid --resume-granularity %q (expected one of: %s)\n", resumeGranularity, strings.Join(resumeGranularities, ", "))
+			os.Exit(1)
+		}
+
+		if !contains(unsafeTechPolicies, unsafeTechPolicy) {
+			fmt.Printf("Error: invalid --unsafe-tech-policy %q (expected one of: %s)\n", unsafeTechPolicy, strings.Join(unsafeTechPolicies, ", "))
+			os.Exit(1)
+		}
+
+		if !contains(techSegments, techSegment) {
+			fmt.Printf("Error: invalid --tech-segment %q (expected one of: %s)\n", techSegment, strings.Join(techSegments, ", "))
+			os.Exit(1)
+		}
+
+		// Validate the combination of tech filter flags
+		filters := techFilters{include: includeList, exclude: excludeList, requireAll: requiredTechs, versions: versionConstraints, prefix: techPrefixMatch}
+		if err := filters.validate(); err != nil {
+			fmt.Printf("Error: %s\n", err)
 			os.Exit(1)
 		}
 
@@ -81,36 +426,79 @@ Examples:
 			}
 		}
 
-		// Read all stdin
-		stdinBytes, err := ioutil.ReadAll(os.Stdin)
-		if err != nil {
-			fmt.Printf("Error reading stdin: %s\n", err)
-			os.Exit(1)
+		// With --validate-only, stop once the configuration is checked, without reading input or scanning
+		if validateOnly {
+			dailyBase := Output
+			if outputDaily {
+				dailyBase = "" // the day directories are created as needed
+			}
+			var problems []string
+			for _, template := range httpxCmds {
+				problems = append(problems, validateRun(commandPrefix, template, inputPath, map[string]string{"output": dailyBase, "resume": resumeFile, "seen-db": seenDBFile})...)
+			}
+			reportValidation(problems)
+			return
 		}
 
-		if len(stdinBytes) == 0 {
-			fmt.Println("No input provided on stdin. Provide JSON or pipe host list into this command.")
-			os.Exit(1)
+		// Read from --input (a file, named pipe or Unix socket) or stdin
+		var input io.Reader = os.Stdin
+		if inputPath != "" {
+			inputFile, err := openInput(inputPath)
+			if err != nil {
+				fmt.Printf("Error opening --input: %s\n", err)
+				os.Exit(1)
+			}
+			defer inputFile.Close()
+			input = inputFile
 		}
 
-		trimmed := strings.TrimSpace(string(stdinBytes))
+		// Drop a BOM or stray control bytes some producers emit before the data, and detect if the input already
+		// contains JSON (its first value parses as JSON). JSON is decoded as it streams in; other input is read whole first.
+		buffered := bufio.NewReaderSize(input, sniffSize)
+		streamJSON := looksLikeJSON(buffered) && inputFormat == "auto"
+
+		var stdinBytes []byte
+		if !streamJSON {
+			stdinBytes, err = io.ReadAll(buffered)
+			if err != nil {
+				fmt.Printf("Error reading input: %s\n", err)
+				os.Exit(1)
+			}
+
+			if len(stdinBytes) == 0 {
+				fmt.Println("No input provided on stdin. Provide JSON or pipe host list into this command.")
+				os.Exit(1)
+			}
+		}
 
 		var reader io.Reader
 
-		// Detect if stdin already contains JSON (starts with [ or {). If not, run techfinder -silent -json
-		if strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{") {
+		// Convert CSV and line inventories to JSON records, decode JSON directly, and otherwise run techfinder -silent -json
+		if streamJSON {
 			if verbose {
 				fmt.Println("Detected JSON on stdin — parsing directly.")
 			}
-			reader = strings.NewReader(string(stdinBytes))
+			reader = buffered
+		} else if inputFormat == "csv" {
+			converted, err := csvToJSON(stdinBytes, csvHostColumn, csvTechColumn, csvTechSeparator)
+			if err != nil {
+				fmt.Printf("Error parsing CSV input: %s\n", err)
+				os.Exit(1)
+			}
+			reader = strings.NewReader(string(converted))
+		} else if inputFormat == "line" {
+			converted, err := lineToJSON(stdinBytes)
+			if err != nil {
+				fmt.Printf("Error parsing line input: %s\n", err)
+				os.Exit(1)
+			}
+			reader = strings.NewReader(string(converted))
 		} else {
 			if verbose {
 				fmt.Println("No JSON detected on stdin — running 'techfinder -silent -json' and piping stdin to it.")
 			}
 			// Run techfinder -silent -json, feeding stdinBytes into its stdin, and capture stdout
-			techfinderCmd := exec.Command("sh", "-c", "techfinder -silent -json")
-			techfinderCmd.Stdin = strings.NewReader(string(stdinBytes))
-			out, err := techfinderCmd.Output()
+			out, err := runTechfinder(stdinBytes, continueOnTechfinderError)
 			if err != nil {
 				fmt.Printf("Error running techfinder: %s\n", err)
 				os.Exit(1)
@@ -118,46 +506,526 @@ Examples:
 			reader = strings.NewReader(string(out))
 		}
 
-		// Open the output file for appending if the --output flag is specified
-		var outputFile *os.File
-		if Output != "" {
-			outputFile, err = os.OpenFile(Output, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		// Open the output file for appending if the --output flag is specified, rotating it past --output-max-size
+		var outputFile *outputWriter
+		Output = expandOutputPath(Output, time.Now())
+		if Output != "" && onlyNonempty {
+			// With --only-nonempty the file is created by the first result line
+			outputFile = openLazyOutputWriter(Output, outputMaxSize, outputDaily)
+			defer outputFile.Close()
+			if validateOutput {
+				outputFile.validateJSON()
+			}
+		} else if Output != "" {
+			outputFile, err = openOutputWriter(Output, outputMaxSize, outputDaily)
 			if err != nil {
 				fmt.Printf("Error opening output file: %s\n", err)
 				os.Exit(1)
 			}
 			defer outputFile.Close()
+			if validateOutput {
+				outputFile.validateJSON()
+			}
+
+			// Record how this run was started with --output-include-command
+			if includeCommand {
+				if _, err := outputFile.WriteString(outputHeader(httpxCmdStr, jsonPretty)); err != nil {
+					fmt.Printf("Error writing to output file: %s\n", err)
+					os.Exit(1)
+				}
+			}
 		}
 
-		decoder := json.NewDecoder(reader)
+		// Count written lines per host for the --output-json-pretty index file
+		var resultIndex *findingIndex
+		if jsonPretty {
+			resultIndex = newFindingIndex()
+		}
+
+		// Skip jobs completed by a previous run and record new completions if --resume is specified
+		var resume *resumeLog
+		if resumeFile != "" {
+			resume, err = openResumeLog(resumeFile, resumeGranularity == "host")
+			if err != nil {
+				fmt.Printf("Error opening resume file: %s\n", err)
+				os.Exit(1)
+			}
+			defer resume.close()
+		}
+
+		// Remember when each host/tech pair was scanned across runs with --seen-db, skipping recent ones with --skip-seen-within
+		var seen *seenDB
+		if seenDBFile != "" {
+			seen, err = openSeenDB(seenDBFile, skipSeenWithin)
+			if err != nil {
+				fmt.Printf("Error opening --seen-db: %s\n", err)
+				os.Exit(1)
+			}
+			defer seen.close()
+		}
+
+		// Suppress lines already written if --dedup-output is specified
+		var deduper *lineDeduper
+		if dedupOutput {
+			deduper = newLineDeduper()
+		}
+
+		// Read the host and tech from the --json-host-key/--json-tech-key fields of other fingerprint tools
+		reader = remapRecordKeys(reader, jsonHostKey, jsonTechKey)
+
+		// With --sample-random the records are shuffled so the --sample-per-tech hosts are picked at random
+		if sampleRandom {
+			reader, err = shuffleRecords(reader)
+			if err != nil {
+				fmt.Printf("Error decoding JSON: %s\n", err)
+				os.Exit(1)
+			}
+		}
+
+		// With --rare-first the records are buffered and ordered so the techs on the fewest hosts are scanned first
+		if rareFirst {
+			reader, err = rareFirstRecords(reader, techSegment, caseSensitive, filters)
+			if err != nil {
+				fmt.Printf("Error decoding JSON: %s\n", err)
+				os.Exit(1)
+			}
+		}
+		sampler := newTechSampler(samplePerTech)
+
+		// --fast-decode unmarshals NDJSON lines on a worker pool so decoding keeps up with fast jobs, and
+		// --strict-json rejects records that don't match the expected schema
+		var decoder recordDecoder = json.NewDecoder(reader)
+		if fastDecode {
+			decoder = newParallelDecoder[HttpxTechData](reader)
+		} else if strictJSON {
+			decoder = newStrictDecoder[strictTechRecord](reader)
+		}
 		var wg sync.WaitGroup
-		semaphore := make(chan struct{}, parallel) // Limit the number of parallel executions
+		sem := semaphore.NewWeighted(int64(parallel)) // Limit the number of parallel executions, heavy techs taking several slots per --tech-weight
+
+		// Warn about stalled jobs if nothing completes within --idle-warn, and time the jobs for --timings or --verbose
+		jobs := newActiveJobs(timings || verbose)
+		stopWatch := make(chan struct{})
+		go jobs.watch(idleWarn, stopWatch)
+
+		// kill -USR1 <pid> prints the progress to stderr without stopping the run
+		go jobs.dumpStatsOnSignal(stopWatch, nil)
+
+		// Track job failures to pause the scan with --error-threshold
+		breaker := newCircuitBreaker(errorThreshold, errorBackoff)
+
+		// Every job runs under the --max-runtime deadline
+		runCtx, cancelRun := runContext(maxRuntime)
+		defer cancelRun()
+
+		// On Ctrl-C, stop the jobs but keep writing the output they produced for up to --shutdown-grace
+		interrupt := handleInterrupt(cancelRun, shutdownGrace)
+
+		// Stop the scan quietly when the reader of stdout goes away
+		stdout := newStdoutWriter(cancelRun)
+
+		// Stop the scan on the first failed job with --abort-on-first-error
+		abort := newJobAbort(abortOnFirstError, cancelRun)
+
+		// With --ordered-output, the terminal lines of parallel jobs are printed in dispatch order
+		ordered := newOrderedOutput(orderedOutputFlag, stdout.jobLine, orderedBuffer)
+
+		// Wordlist lookups are cached across workers and unresolved techs reported at the end
+		wordlists := newWordlistResolver("/root/wordlists", wordlistPaths, caseSensitive)
+
+		// Count the output lines of each tech, to list the techs whose scans hit nothing at the end
+		techLines := newTechOutput()
+
+		// Batch jobs are numbered in their labels, so batches of the same size and tech are told apart
+		var batches atomic.Int64
+
+		// runJob runs the httpx template for techName against hosts, filling {field} placeholders from fields;
+		// call it as a goroutine after acquiring jobWeight(techName) slots of the semaphore, with seq from ordered.begin()
+		runJob := func(hosts []string, techName string, fields map[string]interface{}, seq int) {
+			defer wg.Done()
+			defer sem.Release(jobWeight([]string{techName}, techWeights, parallel, caseSensitive)) // release
+			defer ordered.finish(seq)
+
+			// Terminal lines go through --ordered-output when it is on
+			emit := stdout.jobLine
+			if ordered != nil {
+				emit = func(line string) { ordered.println(seq, line) }
+			}
+
+			// The semaphore may have been acquired after --max-runtime was reached
+			if runCtx.Err() != nil {
+				return
+			}
+
+			// Hosts are fed newline separated; the job label names the host, or the number and size of a --batch-size batch
+			hostInput := strings.Join(hosts, "\n")
+			label := hostInput
+			if len(hosts) > 1 {
+				label = fmt.Sprintf("batch %d, %d hosts", batches.Add(1), len(hosts))
+			}
+
+			// Fill {file:<glob>} placeholders for this job, skipping it when a file is missing
+			templates := make([]string, len(httpxCmds))
+			for i, httpxCmdStr := range httpxCmds {
+				template, missingFile, ok := resolveFilePlaceholders(httpxCmdStr, hostInput, techName)
+				if !ok {
+					fmt.Printf("Skipping %s (%s): no file matches %s\n", label, techName, missingFile)
+					return
+				}
+				templates[i] = template
+			}
+
+			jobKey := fmt.Sprintf("%s (%s)", label, techName)
+			jobID := jobs.start(jobKey)
+			defer func() {
+				duration := jobs.finish(jobID)
+				if timings || verbose {
+					fmt.Printf("Finished %s in %s\n", jobKey, duration.Round(time.Millisecond))
+				}
+			}()
+
+			// Bracket the job with --pre-cmd/--post-cmd; a failing pre-cmd skips the job with --skip-on-pre-cmd-fail.
+			// Hooks count against --max-procs like the commands themselves and are left out once it is reached.
+			if preCmd != "" && procs.take() {
+				hookStr := hookCommand(preCmd, hostInput, techName)
+				if process {
+					fmt.Printf("Running pre-cmd: [%s]\n", hookStr)
+				}
+				if err := runHook(hookStr, workdir, childEnv); err != nil {
+					if skipOnPreCmdFail {
+						fmt.Printf("Skipping %s: pre-cmd failed: %s\n", jobKey, err)
+						return
+					}
+					if verbose {
+						fmt.Printf("Error running pre-cmd for %s: %s\n", jobKey, err)
+					}
+				}
+			}
+			if postCmd != "" {
+				defer func() {
+					if !procs.take() {
+						return
+					}
+					hookStr := hookCommand(postCmd, hostInput, techName)
+					if process {
+						fmt.Printf("Running post-cmd: [%s]\n", hookStr)
+					}
+					if err := runHook(hookStr, workdir, childEnv); err != nil && verbose {
+						fmt.Printf("Error running post-cmd for %s: %s\n", jobKey, err)
+					}
+				}()
+			}
+
+			// Run each --cmd template in turn; one failing doesn't stop the next, but keeps the job out of --resume
+			failed := false
+			headerWritten := false // --output-append-host-comment separator written for this job
+			lines := 0             // output lines of all templates, for the tech accounting and --max-output-lines-per-job
+			capped := false        // stopped at --max-output-lines-per-job
+			defer func() { techLines.record(mapTechNames(techName, techAliases), lines) }()
+			// runTemplate runs the i-th --cmd template, in its own func so its timeout context and output reader are
+			// released before the next template starts; it returns false once --max-procs allows no more commands
+			runTemplate := func(i int, template string) bool {
+				// With several templates, terminal lines are labeled with the number of the --cmd they came from
+				cmdNumber := 0
+				if len(templates) > 1 {
+					cmdNumber = i + 1
+				}
+
+				// Build command string for this techName
+				var cmdStr string
+				if strings.Contains(template, "-path") {
+					// Use the tech's wordlist if one exists, otherwise fall back to inline techName replacement
+					pathToUse, found := wordlists.resolve(techName)
+					if found {
+						if verbose {
+							fmt.Printf("Found wordlist path for tech %s: %s\n", techName, pathToUse)
+						}
+					} else {
+						pathToUse = techName
+						if unsafeTechPolicy == "escape" {
+							pathToUse = shellQuote(techName)
+						}
+						if verbose {
+							fmt.Printf("No wordlist found for tech %s; falling back to inline replacement\n", techName)
+						}
+					}
+					cmdStr = strings.Replace(template, placeholderTech, pathToUse, -1)
+				} else {
+					// Default inline replacement, quoted as one sh word with --unsafe-tech-policy escape
+					techArg := techName
+					if unsafeTechPolicy == "escape" {
+						techArg = shellQuote(techName)
+					}
+					cmdStr = strings.Replace(template, placeholderTech, techArg, -1)
+				}
+
+				// The child environment names the job's host and techs, and its User-Agent for {ua}, which is inserted
+				// as one quoted sh word (empty without --random-ua or --ua-file) since User-Agents hold ; ( and )
+				jobEnv := jobTargetEnv(childEnv, hostInput, techName)
+				ua := randomUserAgent(userAgents)
+				cmdStr = strings.Replace(cmdStr, placeholderUA, shellQuote(ua), -1)
+				if ua != "" {
+					jobEnv = append(jobEnv, userAgentEnv+"="+ua)
+				}
+				// Hosts come from the input, so {host} is inserted as one quoted word that can't run commands
+				cmdStr = strings.Replace(cmdStr, placeholderHost, shellQuote(hostInput), -1)
+				if jsonFields {
+					cmdStr = substituteFields(cmdStr, fields)
+				}
+				cmdStr = prefixCommand(commandPrefix, cmdStr)
+
+				if process {
+					if hostMode == "arg" {
+						fmt.Printf("Running httpx for host %s tech %s: [%s]\n", label, techName, cmdStr)
+					} else {
+						fmt.Printf("Running httpx for host %s tech %s: [echo \"%s\" | %s]\n", label, techName, label, cmdStr)
+					}
+				}
+
+				// Limit the run time with --timeout, or the --timeout-map entry of the tech; --max-output-lines-per-job stops it early
+				ctx, stop := context.WithCancel(runCtx)
+				defer stop()
+				timeout := jobTimeout([]string{techName}, globalTimeout, timeoutMap, caseSensitive)
+				if timeout > 0 {
+					var cancel context.CancelFunc
+					ctx, cancel = context.WithTimeout(ctx, timeout)
+					defer cancel()
+				}
+
+				// Execute httpx command for this host/tech, piping the host on stdin unless --host-mode is arg
+				if !procs.take() {
+					return false
+				}
+				cmd := exec.CommandContext(ctx, "sh", "-c", limits.wrap(cmdStr))
+				cmd.Dir = workdir
+				// Every stop (timeout, --max-runtime, an abort, the line cap or Ctrl-C) cancels ctx, which kills the
+				// whole process group so the programs sh started don't keep the pipes open
+				killProcessGroupOnCancel(cmd)
+				limits.prepare(cmd)
+				if hostMode != "arg" {
+					cmd.Stdin = strings.NewReader(hostInput)
+				}
+				cmd.Env = append(os.Environ(), jobEnv...)
+				stdoutPipe, _ := cmd.StdoutPipe()
+				stderrPipe, _ := cmd.StderrPipe()
+
+				if err := cmd.Start(); err != nil {
+					if verbose {
+						fmt.Printf("Error starting httpx command for %s (%s): %s\n", label, techName, err)
+					}
+					breaker.record(true)
+					abort.fail(jobKey, err)
+					failed = true
+					return true
+				}
+				if err := limits.started(cmd); err != nil && verbose {
+					fmt.Printf("Error applying --nice to %s: %s\n", jobKey, err)
+				}
+
+				// Read stdout and stderr together so their lines are printed in the order the job wrote them
+				scanner := mergeLines(stdoutPipe, stderrPipe)
+				defer scanner.stop()
+				for scanner.Scan() {
+					line := scanner.Text()
+
+					// Kill a runaway job once it passes --max-output-lines-per-job, so it can't fill the disk
+					if maxJobLines > 0 && lines >= maxJobLines {
+						fmt.Printf("Warning: %s printed more than %d lines, stopping it (--max-output-lines-per-job)\n", jobKey, maxJobLines)
+						capped = true
+						stop()
+						break
+					}
+					lines++
+
+					if !quietOutput && stdoutFormat == "jsonl" {
+						// Print each line as a JSON object naming its host and tech
+						emit(formatResultLine(resultHost(line, hosts, label), mapTechNames(techName, techAliases), line, cmdNumber))
+					} else if !quietOutput && cmdNumber > 0 {
+						emit(fmt.Sprintf("[cmd %d] %s", cmdNumber, line))
+					} else if !quietOutput {
+						emit(line)
+					}
+					if Output != "" && deduper.first(line) {
+						entry := line + "\n"
+						lineHost := resultHost(line, hosts, label)
+						if jsonPretty {
+							entry = formatPrettyResult(lineHost, mapTechNames(techName, techAliases), line)
+						}
+						if hostComment && !headerWritten {
+							// Start the job's block with a separator, in the same write so it stays attached to the first line
+							entry = fmt.Sprintf("# ==== %s (%s) ====\n", label, mapTechNames(techName, techAliases)) + entry
+							headerWritten = true
+						}
+						if _, err := outputFile.WriteString(entry); errors.Is(err, errInvalidOutput) {
+							// --validate-output caught a corrupt write; keep the job out of --resume so it runs again
+							fmt.Printf("Error: %s (%s)\n", err, jobKey)
+							failed = true
+						} else if err != nil {
+							if verbose {
+								fmt.Printf("Error writing to output file: %s\n", err)
+							}
+						} else if resultIndex != nil {
+							resultIndex.add(lineHost)
+						}
+					}
+				}
+
+				if err := cmd.Wait(); err != nil && !capped {
+					if runCtx.Err() == nil {
+						breaker.record(true)
+						abort.fail(jobKey, err)
+					}
+					if runCtx.Err() == context.DeadlineExceeded {
+						fmt.Printf("Stopped by --max-runtime: %s\n", jobKey)
+					} else if ctx.Err() == context.DeadlineExceeded {
+						fmt.Printf("Timed out after %s: %s\n", timeout, jobKey)
+					} else if verbose {
+						fmt.Printf("Error waiting for httpx command for %s (%s): %s\n", label, techName, err)
+					}
+					failed = true
+					return true
+				}
+				// A job cut off at --max-output-lines-per-job didn't finish, so keep it out of --resume and --seen-db
+				if capped {
+					breaker.record(true)
+					failed = true
+					return true
+				}
+				breaker.record(false)
+				return true
+			}
+			for i, template := range templates {
+				if capped {
+					break
+				}
+				if !runTemplate(i, template) {
+					return
+				}
+			}
+			if failed {
+				return
+			}
+
+			for _, host := range hosts {
+				if err := resume.record(host, techName); err != nil && verbose {
+					fmt.Printf("Error writing to resume file: %s\n", err)
+				}
+				if err := seen.record(resumeKey(host, techName)); err != nil && verbose {
+					fmt.Printf("Error writing to --seen-db: %s\n", err)
+				}
+			}
+		}
+
+		// With --batch-size hosts sharing a tech are scanned together, batchSize at a time
+		batcher := newHostBatcher(batchSize, caseSensitive)
+
+		// With --fair-hosts the jobs of that many hosts are queued and launched one tech per host in turn
+		fair := newFairQueue(fairHosts)
+		launchFair := func() bool {
+			breaker.wait(runCtx) // pause while more jobs fail than --error-threshold allows
+			if runCtx.Err() != nil || procs.exhausted() {
+				return false
+			}
+			job := fair.pop()
+			wg.Add(1)
+			sem.Acquire(context.Background(), jobWeight([]string{job.tech}, techWeights, parallel, caseSensitive)) // acquire
+			go runJob([]string{job.host}, job.tech, job.fields, ordered.begin())
+			return true
+		}
+
+		dispatched := 0                 // hosts with at least one job launched, for --limit
+		var cidrRecords []HttpxTechData // addresses of an --expand-cidr range still to scan
+		var nullTechHosts []string      // hosts to refingerprint once the input is read, with --refingerprint-null
+		refingerprinted := false
+		// With --normalize-host, host/tech jobs already dispatched for an equivalent host are skipped
+		seenJobs := make(map[string]bool)
 
 		for {
-			var HttpxtechData HttpxTechData
-			if err := decoder.Decode(&HttpxtechData); err == io.EOF {
+			// Pause while more jobs fail than --error-threshold allows
+			breaker.wait(runCtx)
+
+			// Stop launching jobs once --max-runtime or --max-procs is reached
+			if runCtx.Err() != nil || procs.exhausted() {
+				break
+			}
+
+			// Stop reading input once --limit hosts have been dispatched; running jobs still finish below
+			if limit > 0 && dispatched >= limit {
+				if verbose {
+					fmt.Printf("Reached --limit of %d hosts, not reading further input\n", limit)
+				}
 				break
+			}
+
+			var HttpxtechData HttpxTechData
+			if len(cidrRecords) > 0 {
+				HttpxtechData, cidrRecords = cidrRecords[0], cidrRecords[1:]
+			} else if err := decoder.Decode(&HttpxtechData); err == io.EOF {
+				// With --refingerprint-null, give the hosts that had no techs a second techfinder run and scan its records
+				if len(nullTechHosts) == 0 {
+					break
+				}
+				if verbose {
+					fmt.Printf("Re-running techfinder on %d hosts with tech field as null\n", len(nullTechHosts))
+				}
+				decoder, err = refingerprint(nullTechHosts, continueOnTechfinderError)
+				nullTechHosts, refingerprinted = nil, true
+				if err != nil {
+					fmt.Printf("Error re-running techfinder for --refingerprint-null: %s\n", err)
+					break
+				}
+				continue
 			} else if err != nil {
 				fmt.Printf("Error decoding JSON: %s\n", err)
 				os.Exit(1)
 			}
 
+			// With --expand-cidr, a CIDR host is scanned as one record per address, with the same techs
+			if expandCIDR {
+				addrs, isCIDR, err := expandCIDRHost(HttpxtechData.Host, expandCIDRMax)
+				if err != nil {
+					fmt.Printf("Skipping %s: %s\n", HttpxtechData.Host, err)
+					continue
+				}
+				if isCIDR {
+					for _, addr := range addrs {
+						record := HttpxtechData
+						record.Host = addr
+						cidrRecords = append(cidrRecords, record)
+					}
+					continue
+				}
+			}
+
+			HttpxtechData.Host = rewriteHost(HttpxtechData.Host, hostRewrites)
+			if normalizeHosts {
+				HttpxtechData.Host = normalizeHost(HttpxtechData.Host)
+			}
+
 			// Skip processing if tech is nil
 			if HttpxtechData.Tech == nil {
+				if refingerprintNull && !refingerprinted {
+					nullTechHosts = append(nullTechHosts, HttpxtechData.Host)
+					continue
+				}
 				if verbose {
 					fmt.Printf("Skipping URL with tech field as null: %s\n", HttpxtechData.Host)
 				}
 				continue
 			}
 
-			// Build normalized list of tech names (extract part before ":" and lowercase)
+			// With --require-all-tech, only hosts running every listed tech are scanned
+			if missing := missingRequiredTechs(HttpxtechData.Tech, requiredTechs, techSegment, caseSensitive); len(missing) > 0 {
+				if verbose {
+					fmt.Printf("SKIPPED: %s - missing required techs: %s\n", HttpxtechData.Host, strings.Join(missing, ", "))
+				}
+				continue
+			}
+
+			// Build normalized list of tech names (extract the name per --tech-segment and lowercase)
 			var normalizedTechs []string
 			for _, t := range HttpxtechData.Tech {
-				parts := strings.SplitN(t, ":", 2)
-				if len(parts) == 0 {
-					continue
-				}
-				techName := strings.TrimSpace(parts[0])
+				techName, version := splitTech(t, techSegment)
 				if techName == "" {
 					continue
 				}
@@ -168,7 +1036,23 @@ Examples:
 					}
 					continue
 				}
-				norm := strings.ToLower(techName)
+				// With --unsafe-tech-policy drop, skip techs containing any of --unsafe-tech-chars
+				if unsafeTechPolicy == "drop" && hasUnsafeChars(techName, unsafeTechChars) {
+					if verbose {
+						fmt.Printf("Skipping tech %q for host %s (contains --unsafe-tech-chars)\n", techName, HttpxtechData.Host)
+					}
+					continue
+				}
+				// With --tech-version-filter, only keep constrained techs whose version satisfies the constraints
+				if len(versionConstraints) > 0 {
+					if !techVersionAllowed(versionConstraints, techName, version) {
+						if verbose {
+							fmt.Printf("Skipping tech %s for host %s (version %q not allowed by --tech-version-filter)\n", techName, HttpxtechData.Host, version)
+						}
+						continue
+					}
+				}
+				norm := techCase(techName, caseSensitive)
 				normalizedTechs = append(normalizedTechs, norm)
 			}
 
@@ -180,17 +1064,27 @@ Examples:
 			}
 
 			// For each tech (one httpx run per tech), apply include/exclude and launch job
+			launched := false
+			stopped := false // dispatching stopped before all of the host's techs were considered
+			// With --resume-granularity host, hold the host open until all its jobs are dispatched
+			resume.expect(HttpxtechData.Host, 1)
+			var planned []string   // techs listed by --print-plan
+			var hostJobs []fairJob // jobs queued for --fair-hosts
+			// Decode the record's other fields only when --json-fields substitutes them
+			var fields map[string]interface{}
+			if jsonFields {
+				fields = HttpxtechData.extraFields()
+			}
 			for _, tech := range normalizedTechs {
-				// Apply include/exclude logic
-				if len(includeList) > 0 {
-					if !contains(includeList, tech) {
-						if verbose {
-							fmt.Printf("Skipping tech %s for host %s (not in include list)\n", tech, HttpxtechData.Host)
-						}
-						continue
+				// Apply the include list, then remove the excluded techs from what it kept
+				if len(includeList) > 0 && !matchesTechList(includeList, tech, techPrefixMatch) {
+					if verbose {
+						fmt.Printf("Skipping tech %s for host %s (not in include list)\n", tech, HttpxtechData.Host)
 					}
-				} else if len(excludeList) > 0 {
-					if contains(excludeList, tech) {
+					continue
+				}
+				if len(excludeList) > 0 {
+					if matchesTechList(excludeList, tech, techPrefixMatch) {
 						if verbose {
 							fmt.Printf("Skipping tech %s for host %s (in exclude list)\n", tech, HttpxtechData.Host)
 						}
@@ -198,129 +1092,184 @@ Examples:
 					}
 				}
 
-				wg.Add(1)
-				semaphore <- struct{}{} // acquire
-				go func(host, techName string) {
-					defer wg.Done()
-					defer func() { <-semaphore }() // release
-
-					// Build command string for this techName
-					var cmdStr string
-					if strings.Contains(httpxCmdStr, "-path") {
-						// Try candidate paths:
-						// 1) techName as provided (maybe user passed "jenkins.txt")
-						// 2) ./wordlists/<techName>
-						// 3) ./wordlists/<techName>.txt
-						// If none exist, fallback to inline techName replacement.
-						candidate := techName
-						var pathToUse string
-						if fileExists(candidate) {
-							pathToUse = candidate
-							if verbose {
-								fmt.Printf("Using existing path for tech %s: %s\n", techName, pathToUse)
-							}
-						} else {
-							wordlistsDir := "/root/wordlists"
-							try1 := filepath.Join(wordlistsDir, candidate)
-							try2 := try1
-							if !strings.HasSuffix(strings.ToLower(try1), ".txt") {
-								try2 = try1 + ".txt"
-							}
-							if fileExists(try1) {
-								pathToUse = try1
-								if verbose {
-									fmt.Printf("Found wordlist path for tech %s: %s\n", techName, pathToUse)
-								}
-							} else if fileExists(try2) {
-								pathToUse = try2
-								if verbose {
-									fmt.Printf("Found wordlist path for tech %s: %s\n", techName, pathToUse)
-								}
-							} else {
-								// fallback to inline replacement
-								pathToUse = techName
-								if verbose {
-									fmt.Printf("No wordlist found for tech %s; falling back to inline replacement\n", techName)
-								}
-							}
+				if normalizeHosts {
+					if seenJobs[resumeKey(HttpxtechData.Host, tech)] {
+						if verbose {
+							fmt.Printf("Skipping tech %s for host %s (duplicate of an already dispatched host)\n", tech, HttpxtechData.Host)
 						}
-						cmdStr = strings.Replace(httpxCmdStr, "{tech}", pathToUse, -1)
-					} else {
-						// Default inline replacement
-						cmdStr = strings.Replace(httpxCmdStr, "{tech}", techName, -1)
+						continue
 					}
+					seenJobs[resumeKey(HttpxtechData.Host, tech)] = true
+				}
 
-					if process {
-						fmt.Printf("Running httpx for host %s tech %s: [echo \"%s\" | %s]\n", host, techName, host, cmdStr)
+				if !sampler.keep(tech) {
+					if verbose {
+						fmt.Printf("Skipping tech %s for host %s (--sample-per-tech reached)\n", tech, HttpxtechData.Host)
 					}
+					continue
+				}
 
-					// Execute httpx command for this host/tech
-					cmd := exec.Command("sh", "-c", cmdStr)
-					cmd.Stdin = strings.NewReader(host)
-					stdoutPipe, _ := cmd.StdoutPipe()
-					stderrPipe, _ := cmd.StderrPipe()
-
-					if err := cmd.Start(); err != nil {
-						if verbose {
-							fmt.Printf("Error starting httpx command for %s (%s): %s\n", host, techName, err)
-						}
-						return
+				if seen.recent(resumeKey(HttpxtechData.Host, tech)) {
+					if verbose {
+						fmt.Printf("Skipping tech %s for host %s (scanned within --skip-seen-within)\n", tech, HttpxtechData.Host)
 					}
+					continue
+				}
 
-					scanner := bufio.NewScanner(io.MultiReader(stdoutPipe, stderrPipe))
-					for scanner.Scan() {
-						line := scanner.Text()
-						fmt.Println(line)
-						if Output != "" {
-							if _, err := outputFile.WriteString(line + "\n"); err != nil && verbose {
-								fmt.Printf("Error writing to output file: %s\n", err)
-							}
-						}
+				if resume.has(HttpxtechData.Host, tech) {
+					if verbose {
+						fmt.Printf("Skipping tech %s for host %s (already completed in resume file)\n", tech, HttpxtechData.Host)
 					}
+					continue
+				}
+
+				breaker.wait(runCtx) // pause while more jobs fail than --error-threshold allows
+				if runCtx.Err() != nil || procs.exhausted() {
+					stopped = true
+					break
+				}
+
+				launched = true
+				if printPlan {
+					planned = append(planned, tech)
+					continue
+				}
+				resume.expect(HttpxtechData.Host, 1)
 
-					if err := cmd.Wait(); err != nil && verbose {
-						fmt.Printf("Error waiting for httpx command for %s (%s): %s\n", host, techName, err)
+				if batcher != nil {
+					if batch, full := batcher.add(HttpxtechData.Host, []string{tech}); full {
+						wg.Add(1)
+						sem.Acquire(context.Background(), jobWeight(batch.techs, techWeights, parallel, caseSensitive)) // acquire
+						go runJob(batch.hosts, tech, nil, ordered.begin())
 					}
-				}(HttpxtechData.Host, tech)
+					continue
+				}
+
+				if fair != nil {
+					hostJobs = append(hostJobs, fairJob{HttpxtechData.Host, tech, fields})
+					continue
+				}
+
+				wg.Add(1)
+				sem.Acquire(context.Background(), jobWeight([]string{tech}, techWeights, parallel, caseSensitive)) // acquire
+				go runJob([]string{HttpxtechData.Host}, tech, fields, ordered.begin())
+			}
+			if launched {
+				dispatched++
+			}
+			// A host without jobs, or with techs left undispatched, keeps its hold and is never recorded
+			if launched && !stopped && !printPlan {
+				if err := resume.release(HttpxtechData.Host); err != nil && verbose {
+					fmt.Printf("Error writing to resume file: %s\n", err)
+				}
+			}
+			fair.add(hostJobs)
+			for fair.full() {
+				if !launchFair() {
+					break
+				}
+			}
+			if len(planned) > 0 {
+				printPlanEntry(HttpxtechData.Host, planned)
 			}
 		}
 
-		wg.Wait() // Wait for all goroutines to finish
-	},
-}
+		if printPlan {
+			return
+		}
 
-// Helper function to parse tech input (supports both comma-separated values and file paths)
-func HttpxparseTechInput(input string) ([]string, error) {
-	if input == "" {
-		return []string{}, nil
-	}
+		// Launch the --fair-hosts jobs still queued at the end of the input
+		for !fair.empty() {
+			if !launchFair() {
+				break
+			}
+		}
 
-	// Check if input is a file that exists
-	if _, err := os.Stat(input); err == nil {
-		// It's a file, read lines from the file
-		file, err := os.Open(input)
-		if err != nil {
-			return nil, err
+		// Scan the partially filled --batch-size batches left at the end of the input
+		for _, batch := range batcher.flush() {
+			breaker.wait(runCtx) // pause while more jobs fail than --error-threshold allows
+			if runCtx.Err() != nil || procs.exhausted() {
+				break
+			}
+			wg.Add(1)
+			sem.Acquire(context.Background(), jobWeight(batch.techs, techWeights, parallel, caseSensitive)) // acquire
+			go runJob(batch.hosts, batch.techs[0], nil, ordered.begin())
 		}
-		defer file.Close()
 
-		var techs []string
-		scanner := bufio.NewScanner(file)
-		for scanner.Scan() {
-			tech := strings.TrimSpace(scanner.Text())
-			if tech != "" {
-				techs = append(techs, strings.ToLower(tech))
+		// Wait for all goroutines to finish, or only --shutdown-grace once interrupted, printing what they left held
+		// and dropping any later lines of theirs
+		if !interrupt.wait(&wg) {
+			ordered.drain()
+			stdout.endJobs()
+		}
+		close(stopWatch)
+
+		if resultIndex != nil {
+			if err := resultIndex.write(indexPath(Output)); err != nil {
+				fmt.Printf("Error writing index file: %s\n", err)
 			}
 		}
-		return techs, scanner.Err()
-	}
 
-	// Otherwise, treat as comma-separated list
-	techs := strings.Split(input, ",")
-	for i := range techs {
-		techs[i] = strings.TrimSpace(strings.ToLower(techs[i]))
-	}
-	return techs, nil
+		// Point latest<ext> at this run's output with --output-symlink-latest, unless --only-nonempty left none
+		if symlinkLatest && outputFile.Written() {
+			if err := linkLatest(Output, outputFile.Path()); err != nil {
+				fmt.Printf("Error linking latest output: %s\n", err)
+			}
+		}
+
+		// With --summary-json the run stats are printed as the last stdout line, whatever the exit code
+		printSummaryJSON := func(stopped string, exitCode int) {
+			if !summaryJSON {
+				return
+			}
+			summary := runSummary{Command: "httpx", Seconds: jobs.elapsed().Round(time.Millisecond).Seconds(), Stopped: stopped, ExitCode: exitCode}
+			techLines.fill(&summary)
+			stdout.println(summary.String())
+		}
+
+		// Deferred closes don't run on os.Exit, so close the outputs before exiting with the --max-runtime,
+		// --abort-on-first-error or interrupt code
+		if runCtx.Err() == context.DeadlineExceeded || abort.failed() || interrupt.interrupted() {
+			if outputFile != nil {
+				outputFile.Close()
+			}
+			resume.close()
+			seen.close()
+			if interrupt.interrupted() {
+				if resumeFile != "" {
+					fmt.Printf("Completed jobs saved to %s; rerun with the same --resume to continue\n", resumeFile)
+				}
+				printSummaryJSON("interrupted", exitInterrupted)
+				os.Exit(exitInterrupted)
+			}
+			if abort.failed() {
+				printSummaryJSON("job-error", exitJobError)
+				os.Exit(exitJobError)
+			}
+			fmt.Printf("Reached --max-runtime of %s, stopped with partial results\n", maxRuntime)
+			printSummaryJSON("max-runtime", exitMaxRuntime)
+			os.Exit(exitMaxRuntime)
+		}
+
+		if timings || verbose {
+			jobs.printSlowest()
+		}
+
+		// List which techs' scans printed lines and which came back empty
+		if !silent {
+			hit, empty := techLines.summary()
+			if len(hit) > 0 {
+				fmt.Printf("techs with output: [%s]\n", strings.Join(hit, ", "))
+			}
+			if len(empty) > 0 {
+				fmt.Printf("techs without output: [%s]\n", strings.Join(empty, ", "))
+			}
+			if missing := wordlists.missingTechs(); len(missing) > 0 {
+				fmt.Printf("missing wordlists: [%s]\n", strings.Join(missing, ", "))
+			}
+		}
+		printSummaryJSON("", 0)
+	},
 }
 
 // Utility function to check if an item is in a slice
@@ -345,11 +1294,95 @@ func fileExists(path string) bool {
 func init() {
 	rootCmd.AddCommand(httpxCmd)
 
-	httpxCmd.Flags().StringP("cmd", "c", "", "The httpx command template")
+	httpxCmd.Flags().StringArrayP("cmd", "c", nil, "The httpx command template; repeat it to run several commands in turn for each job")
+	httpxCmd.Flags().StringArray("var", nil, "Per-run placeholder name=value substituted for {name} in the command template, repeatable (e.g. --var tpl=~/mytemplates)")
+	httpxCmd.Flags().String("cmd-file", "", "File containing the httpx command template, as an alternative to --cmd")
+	httpxCmd.Flags().String("stdout-format", "raw", "Format of the output printed to the terminal: raw (command output lines) or jsonl (one {\"host\",\"tech\",\"output\"} object per line)")
+	httpxCmd.Flags().Bool("silent", false, "Don't print the banner or the per-tech output summary, so stdout only carries the scan output")
+	httpxCmd.Flags().Bool("quiet-output", false, "Don't print command output to the terminal, only write it to --output")
 	httpxCmd.Flags().Bool("verbose", false, "Enable verbose output for debugging purposes.")
 	httpxCmd.Flags().Bool("process", false, "Show which URL is running on httpx.")
 	httpxCmd.Flags().Int("parallel", 50, "Number of parallel processes")
-	httpxCmd.Flags().StringP("output", "o", "", "File to save output")
-	httpxCmd.Flags().StringP("exclude-tech", "e", "", "Comma-separated list of technologies to exclude, or path to a file with technologies (one per line)")
+	httpxCmd.Flags().Int("max-procs", 0, "Hard cap on the total number of commands started in the run, independent of --parallel (0 for no cap)")
+	httpxCmd.Flags().String("tech-weight", "", "Parallel slots taken by a job per tech, e.g. \"confluence=4,default=1\", so heavy techs run at lower concurrency")
+	httpxCmd.Flags().String("seen-db", "", "File remembering when each host|tech pair was last scanned, kept across runs (see --skip-seen-within)")
+	httpxCmd.Flags().Duration("skip-seen-within", 0, "Skip host/tech pairs the --seen-db saw scanned less than this long ago, e.g. 24h (0 only records)")
+	httpxCmd.Flags().String("resume", "", "File recording completed host|tech jobs; jobs already in it are skipped so an interrupted run can continue")
+	httpxCmd.Flags().String("resume-granularity", "tech", "What --resume records: tech (each host|tech job) or host (a host once all its jobs completed)")
+	httpxCmd.Flags().Bool("sequential", false, "Run one job at a time in input order so the output is identical across runs (overrides --parallel)")
+	httpxCmd.Flags().Bool("concurrency-auto", false, "Size the number of parallel processes from the CPU count (an explicit --parallel wins)")
+	httpxCmd.Flags().Int("sample-per-tech", 0, "Only scan the first N hosts of each technology for a quick coverage check (0 to scan all)")
+	httpxCmd.Flags().String("json-host-key", "host", "JSON key holding the host in the input records, e.g. url")
+	httpxCmd.Flags().String("json-tech-key", "tech", "JSON key holding the techs in the input records, e.g. technologies (a list of names or of {name, version} objects, an object keyed by name or a comma-separated string)")
+	httpxCmd.Flags().Bool("case-sensitive", false, "Match and substitute tech names exactly as the input has them instead of lowercasing them")
+	httpxCmd.Flags().Bool("rare-first", false, "Scan the techs found on the fewest hosts first, so an early stop still covers the uncommon ones (buffers the whole input)")
+	httpxCmd.Flags().Bool("sample-random", false, "Pick the --sample-per-tech hosts at random instead of the first ones (buffers the whole input)")
+	httpxCmd.Flags().Bool("print-plan", false, "Print the {\"host\", \"tech\"} record of techs each host would be scanned for after filtering, then exit without scanning")
+	httpxCmd.Flags().Bool("ordered-output", false, "Run jobs with --parallel but print their output in input order, holding the output of jobs that finish early")
+	httpxCmd.Flags().String("ordered-buffer", "64MB", "Memory for output held by --ordered-output before it spills to a temp file, e.g. 16MB (0 for no limit)")
+	httpxCmd.Flags().Bool("expand-cidr", false, "Scan a CIDR host such as 192.168.0.0/24 as one job per address, each with the record's techs")
+	httpxCmd.Flags().Int("expand-cidr-max", 65536, "Largest CIDR range --expand-cidr expands; bigger ranges are skipped")
+	httpxCmd.Flags().Duration("shutdown-grace", 10*time.Second, "On Ctrl-C, how long running jobs get to stop and have their output written before the outputs are closed")
+	httpxCmd.Flags().Bool("abort-on-first-error", false, "Stop the whole scan and exit with code 4 as soon as a command fails, e.g. while testing a template")
+	httpxCmd.Flags().Bool("validate-only", false, "Check the flags, filter and map files and the command template, then exit without reading input or scanning")
+	httpxCmd.Flags().Int("limit", 0, "Only scan the first N hosts that have technologies left after filtering (0 for no limit)")
+	httpxCmd.Flags().StringP("output", "o", "", "File to save output; {timestamp} is replaced with the run's start time")
+	httpxCmd.Flags().Bool("output-daily", false, "Write --output under YYYY/MM/DD/ directories next to the given path, switching directory at midnight")
+	httpxCmd.Flags().Bool("summary-json", false, "Print the run stats (jobs, lines, techs with and without output, duration, exit code) as a JSON object on the last stdout line")
+	httpxCmd.Flags().Bool("only-nonempty", false, "Only create the --output file once a scan prints a line, so runs without results leave no empty file")
+	httpxCmd.Flags().Bool("output-symlink-latest", false, "At the end of the run, point a latest<ext> symlink next to the output at this run's file (needs {timestamp} in --output)")
+	httpxCmd.Flags().Bool("random-ua", false, "Pick a random User-Agent per job for the {ua} placeholder and the VULNTECHFINDER_UA environment variable")
+	httpxCmd.Flags().String("ua-file", "", "File with one User-Agent per line used instead of the built-in list (implies --random-ua)")
+	httpxCmd.Flags().String("workdir", "", "Directory the commands run in, so relative wordlist/template paths resolve against it (default: current directory)")
+	httpxCmd.Flags().String("command-prefix", "", "Wrapper prepended to every resolved command, e.g. \"proxychains -q\" or \"nice -n 10\"")
+	httpxCmd.Flags().String("pre-cmd", "", "Command run before each job, with {host} and {tech} substituted (e.g. DNS warm-up or logging)")
+	httpxCmd.Flags().String("post-cmd", "", "Command run after each job, with {host} and {tech} substituted")
+	httpxCmd.Flags().Bool("skip-on-pre-cmd-fail", false, "Skip a job when its --pre-cmd exits with an error")
+	httpxCmd.Flags().Bool("output-append-host-comment", false, "Write a \"# ==== host (tech) ====\" separator before each job's lines in --output (use with --sequential to keep blocks contiguous)")
+	httpxCmd.Flags().Bool("validate-output", false, "Read every write to the JSON --output back and check it parses, failing the job on corrupt output")
+	httpxCmd.Flags().Bool("output-json-pretty", false, "Write each output line to --output as an indented JSON object {host, tech, output} and a host -> count index to <output>-index.json")
+	httpxCmd.Flags().String("path-map", "", "File with one tech=/path/to/wordlist per line, used for {tech} in -path before guessing a wordlist from the tech name")
+	httpxCmd.Flags().String("tech-map-output", "", "Tech aliases \"raw=canonical,...\" (or a file with one per line) used for tech names in output files; filters and {tech} still use the raw names")
+	httpxCmd.Flags().Bool("output-include-command", false, "Start the run's part of --output with a line recording the vulntechfinder version, start time and effective command")
+	httpxCmd.Flags().String("output-max-size", "", "Rotate --output to name.1, name.2, ... once it would grow past this size, e.g. 100MB (empty for no limit)")
+	httpxCmd.Flags().Bool("dedup-output", false, "Write each output line only once, ignoring timestamps and colors when comparing")
+	httpxCmd.Flags().Int("fair-hosts", 0, "Queue the tech jobs of up to N hosts and launch them round-robin, one tech per host in turn, so hosts with many techs don't starve the others")
+	httpxCmd.Flags().Int("batch-size", 0, "Feed up to N hosts with the same filtered techs to one process on stdin instead of one process per host (no {field} placeholders)")
+	httpxCmd.Flags().Float64("error-threshold", 0, "Pause launching jobs for --error-backoff when more than this fraction of the last 20 jobs failed, e.g. 0.5 (0 to disable)")
+	httpxCmd.Flags().Duration("error-backoff", 30*time.Second, "How long --error-threshold pauses new jobs before probing again")
+	httpxCmd.Flags().String("unsafe-tech-policy", "keep", "What to do with techs containing --unsafe-tech-chars: keep, drop or escape (insert {tech} as one quoted sh word)")
+	httpxCmd.Flags().String("unsafe-tech-chars", defaultUnsafeTechChars, "Characters that make a tech name unsafe for --unsafe-tech-policy")
+	httpxCmd.Flags().String("tech-segment", "first", "Name used for vendor:product:version techs: first (the vendor), product or vendor-product, e.g. apache:tomcat:9 gives apache, tomcat or apache-tomcat")
+	httpxCmd.Flags().Bool("refingerprint-null", false, "Run techfinder again over the input hosts whose tech field is null and scan them with the techs it finds")
+	httpxCmd.Flags().Bool("continue-on-techfinder-error", false, "When techfinder exits with an error after printing some JSON, scan the hosts it did fingerprint instead of aborting")
+	httpxCmd.Flags().Bool("strict-json", false, "Exit on input records with unknown fields, wrong types or no host instead of ignoring the anomaly")
+	httpxCmd.Flags().Bool("fast-decode", false, "Decode NDJSON input (one record per line) on a pool of goroutines, for huge inputs with fast commands")
+	httpxCmd.Flags().String("input", "", "Read input from this file, named pipe or Unix socket instead of stdin")
+	httpxCmd.Flags().String("input-format", "auto", "Input format: auto (JSON, or a host list run through techfinder), csv or line (\"host tech1,tech2\" per line)")
+	httpxCmd.Flags().Int("csv-host-column", 0, "Zero-based CSV column holding the host (--input-format csv)")
+	httpxCmd.Flags().Int("csv-tech-column", 1, "Zero-based CSV column holding the tech list (--input-format csv)")
+	httpxCmd.Flags().String("csv-tech-separator", ";", "Separator between techs in the CSV tech column (--input-format csv)")
+	httpxCmd.Flags().Bool("tech-prefix-match", false, "Match --include-tech/--exclude-tech entries as prefixes, e.g. wordpress also matches wordpress-plugin-x")
+	httpxCmd.Flags().String("require-all-tech", "", "Only scan hosts running all of these technologies, comma-separated or a file with one per line (e.g. \"php,wordpress\")")
+	httpxCmd.Flags().StringArray("tech-version-filter", nil, "Only scan techs whose detected version satisfies a constraint, e.g. \"jira<9.4.0\" or \"confluence>=7.0,confluence<7.19\" (repeatable)")
+	httpxCmd.Flags().Bool("exclude-noise", false, "Also exclude a built-in list of low-signal techs (CDNs, analytics, common JS libraries such as jquery, google-analytics, cloudflare)")
+	httpxCmd.Flags().StringP("exclude-tech", "e", "", "Comma-separated list of technologies to exclude (applied after --include-tech), or path to a file with technologies (one per line)")
 	httpxCmd.Flags().StringP("include-tech", "i", "", "Comma-separated list of technologies to include (only these will be processed), or path to a file with technologies (one per line)")
+	httpxCmd.Flags().String("extra-args", "", "Extra arguments appended to the command template before execution (e.g. \"-rl 50 -timeout 10\")")
+	httpxCmd.Flags().Bool("insecure", false, "Append the command's known flag for skipping TLS certificate verification")
+	httpxCmd.Flags().String("insecure-flags", "", "Override or add skip-verify flags per tool for --insecure, e.g. \"httpx=-some-flag,curl=-k\"")
+	httpxCmd.Flags().Bool("normalize-host", false, "Canonicalize hosts (lowercase, no path, trailing dot or default port) and scan each host/tech only once")
+	httpxCmd.Flags().StringArray("host-rewrite", nil, "Regex rule pattern=>replacement applied to each host before scanning, repeatable (e.g. \"^=>www.\" or \":\\d+$=>\")")
+	httpxCmd.Flags().Bool("json-fields", false, "Replace {field} placeholders with extra fields of the input JSON, e.g. {status} or {title}")
+	httpxCmd.Flags().String("env-file", "", "File with KEY=VALUE lines added to the environment of each command (e.g. API tokens)")
+	httpxCmd.Flags().String("host-mode", "stdin", "How the host is passed to the command: stdin, arg (replaces {host}) or both")
+	httpxCmd.Flags().Bool("timings", false, "Print how long each job took and the slowest jobs at the end (also shown with --verbose)")
+	httpxCmd.Flags().Duration("max-runtime", 0, "Stop the whole scan after this long (e.g. 2h): no new jobs start, running ones are killed and the exit code is 3")
+	httpxCmd.Flags().Int("nice", 0, "Niceness (0-19) of the scan commands, so heavy parallel runs leave the box responsive")
+	httpxCmd.Flags().String("max-memory", "", "Virtual memory limit per scan process, e.g. 2GB (applied with ulimit -v)")
+	httpxCmd.Flags().Duration("max-cpu-time", 0, "CPU time limit per scan process, e.g. 10m (applied with ulimit -t)")
+	httpxCmd.Flags().Int("max-output-lines-per-job", 0, "Kill a job once it printed this many lines, with a warning, so a runaway command can't fill the disk (0 for no limit)")
+	httpxCmd.Flags().Duration("timeout", 0, "Kill a job that runs longer than this (e.g. 10m, 0 for no timeout)")
+	httpxCmd.Flags().String("timeout-map", "", "Per-tech timeout overrides, e.g. \"confluence=15m,default=5m\"")
+	httpxCmd.Flags().Duration("idle-warn", 0, "Warn with the list of running jobs if no job completes within this interval (e.g. 5m, 0 to disable)")
 }
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// UTF-8 byte order mark some producers write at the start of their output
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// stripLeadingNoise removes UTF-8 BOMs, whitespace and control bytes before the first real character,
+// so JSON detection and decoding see the input as it was meant
+func stripLeadingNoise(input []byte) []byte {
+	for len(input) > 0 {
+		if bytes.HasPrefix(input, utf8BOM) {
+			input = input[len(utf8BOM):]
+			continue
+		}
+		if c := input[0]; c <= ' ' || c == 0x7f {
+			input = input[1:]
+			continue
+		}
+		break
+	}
+	return input
+}
+
+// skipLeadingNoise discards the BOMs, whitespace and control bytes stripLeadingNoise would remove from the start
+// of a stream and returns the first remaining byte without consuming it, or 0 at the end of the input
+func skipLeadingNoise(r *bufio.Reader) byte {
+	for {
+		if b, err := r.Peek(len(utf8BOM)); err == nil && bytes.Equal(b, utf8BOM) {
+			r.Discard(len(utf8BOM))
+			continue
+		}
+		b, err := r.Peek(1)
+		if err != nil {
+			return 0
+		}
+		if c := b[0]; c <= ' ' || c == 0x7f {
+			r.Discard(1)
+			continue
+		}
+		return b[0]
+	}
+}
+
+// sniffSize is the read buffer of the input, and so how far ahead the JSON detection can look at the first value
+const sniffSize = 1 << 20
+
+// peekReader reads a bufio.Reader ahead without consuming it, blocking only when everything buffered has been read
+type peekReader struct {
+	r   *bufio.Reader
+	off int
+}
+
+func (p *peekReader) Read(b []byte) (int, error) {
+	n := p.r.Buffered()
+	if n <= p.off {
+		n = p.off + 1
+	}
+	data, err := p.r.Peek(n)
+	if len(data) <= p.off {
+		if err == nil {
+			err = io.EOF
+		}
+		return 0, err
+	}
+	copied := copy(b, data[p.off:])
+	p.off += copied
+	return copied, nil
+}
+
+// looksLikeJSON reports whether the input, after skipLeadingNoise, starts with a value that actually parses as
+// JSON, rather than a host line that merely begins with { or [. The first value is trial decoded from the read
+// buffer, so nothing is consumed; a value larger than the buffer is trusted on its first byte.
+func looksLikeJSON(r *bufio.Reader) bool {
+	if first := skipLeadingNoise(r); first != '[' && first != '{' {
+		return false
+	}
+	var value json.RawMessage
+	err := json.NewDecoder(&peekReader{r: r}).Decode(&value)
+	return err == nil || errors.Is(err, bufio.ErrBufferFull)
+}
+
+// openInput opens --input for streaming: a regular file, a named pipe (blocking until a writer opens it)
+// or a Unix domain socket, which is connected to
+func openInput(path string) (io.ReadCloser, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Mode()&os.ModeSocket != 0 {
+		return net.Dial("unix", path)
+	}
+	return os.Open(path)
+}
+
+// Supported values for --input-format; auto detects JSON and otherwise runs techfinder on the host list
+var inputFormats = []string{"auto", "csv", "line"}
+
+// csvToJSON converts CSV rows into newline-delimited {"host":..., "tech":[...]} objects, taking the host and
+// the techSeparator-separated tech list from the given zero-based columns. A header row whose host cell is "host" is skipped.
+func csvToJSON(data []byte, hostColumn, techColumn int, techSeparator string) ([]byte, error) {
+	if hostColumn < 0 || techColumn < 0 {
+		return nil, fmt.Errorf("CSV columns can't be negative")
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if hostColumn >= len(record) {
+			return nil, fmt.Errorf("row %d has no host column %d", row, hostColumn)
+		}
+
+		host := strings.TrimSpace(record[hostColumn])
+		if host == "" || (row == 1 && strings.EqualFold(host, "host")) {
+			continue
+		}
+
+		techs := []string{}
+		if techColumn < len(record) {
+			for _, tech := range strings.Split(record[techColumn], techSeparator) {
+				if tech = strings.TrimSpace(tech); tech != "" {
+					techs = append(techs, tech)
+				}
+			}
+		}
+
+		if err := encoder.Encode(TechData{Host: host, Tech: techs}); err != nil {
+			return nil, err
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// lineToJSON converts "host tech1,tech2" lines into newline-delimited {"host":..., "tech":[...]} objects.
+// Everything after the host is the comma-separated tech list; blank lines and # comments are skipped.
+func lineToJSON(data []byte) ([]byte, error) {
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		techs := []string{}
+		for _, tech := range strings.Split(strings.Join(fields[1:], ","), ",") {
+			if tech = strings.TrimSpace(tech); tech != "" {
+				techs = append(techs, tech)
+			}
+		}
+
+		if err := encoder.Encode(TechData{Host: fields[0], Tech: techs}); err != nil {
+			return nil, err
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// runTechfinder runs "techfinder -silent -json" over the host list and returns its JSON output. techfinder's
+// stderr is kept separate and included in the error when it fails or prints something that isn't JSON.
+// With keepPartial, a techfinder exiting non-zero after printing some records only causes a warning and
+// the complete lines it printed are returned.
+func runTechfinder(hosts []byte, keepPartial bool) ([]byte, error) {
+	var stderr bytes.Buffer
+	techfinderCmd := exec.Command("sh", "-c", "techfinder -silent -json")
+	techfinderCmd.Stdin = bytes.NewReader(hosts)
+	techfinderCmd.Stderr = &stderr
+
+	out, err := techfinderCmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !keepPartial || !errors.As(err, &exitErr) || bytes.LastIndexByte(out, '\n') < 0 {
+			return nil, fmt.Errorf("%s%s", err, techfinderStderr(stderr.Bytes()))
+		}
+		// Drop a record cut off by the failure
+		out = out[:bytes.LastIndexByte(out, '\n')+1]
+		fmt.Printf("Warning: techfinder failed (%s), scanning the output it produced%s\n", err, techfinderStderr(stderr.Bytes()))
+	}
+
+	if trimmed := bytes.TrimSpace(stripLeadingNoise(out)); len(trimmed) > 0 && trimmed[0] != '{' && trimmed[0] != '[' {
+		firstLine := strings.SplitN(string(trimmed), "\n", 2)[0]
+		return nil, fmt.Errorf("output is not JSON: %q%s", firstLine, techfinderStderr(stderr.Bytes()))
+	}
+	return out, nil
+}
+
+// refingerprint runs techfinder again over hosts whose input record had a null tech, for --refingerprint-null,
+// and returns a decoder over the records it produced
+func refingerprint(hosts []string, keepPartial bool) (recordDecoder, error) {
+	out, err := runTechfinder([]byte(strings.Join(hosts, "\n")+"\n"), keepPartial)
+	if err != nil {
+		return nil, err
+	}
+	return json.NewDecoder(bytes.NewReader(out)), nil
+}
+
+// techfinderStderr formats techfinder's stderr for an error message
+func techfinderStderr(stderr []byte) string {
+	if msg := strings.TrimSpace(string(stderr)); msg != "" {
+		return "\ntechfinder stderr: " + msg
+	}
+	return ""
+}
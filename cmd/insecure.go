@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Flags that make each known tool skip TLS certificate verification, used by --insecure.
+// nuclei and httpx don't verify certificates by default and have no such flag, so --insecure is rejected for them
+// unless --insecure-flags names one.
+var insecureFlags = map[string]string{
+	"curl":        "-k",
+	"wget":        "--no-check-certificate",
+	"gobuster":    "-k",
+	"feroxbuster": "-k",
+}
+
+// insecureFlagFor returns the skip-verify flag for the tool the template runs, checking the
+// user overrides before the built-in map. The tool is the first word of the template, falling back to the subcommand name.
+func insecureFlagFor(template, subcommand string, overrides map[string]string) (string, bool) {
+	var names []string
+	if fields := strings.Fields(template); len(fields) > 0 {
+		names = append(names, strings.ToLower(filepath.Base(fields[0])))
+	}
+	names = append(names, subcommand)
+
+	for _, name := range names {
+		if flag, ok := overrides[name]; ok {
+			return flag, true
+		}
+		if flag, ok := insecureFlags[name]; ok {
+			return flag, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,14 @@
+package cmd
+
+// Environment variables naming the job's target, so wrapper scripts used as --cmd can read them instead of
+// parsing the {host}/{tech} substitution
+const (
+	hostEnv = "VULNTECHFINDER_HOST"
+	techEnv = "VULNTECHFINDER_TECH"
+)
+
+// jobTargetEnv returns a copy of env with the job's host (newline-separated hosts for a batch) and
+// comma-separated techs added
+func jobTargetEnv(env []string, hostInput, techs string) []string {
+	return append(append([]string(nil), env...), hostEnv+"="+hostInput, techEnv+"="+techs)
+}
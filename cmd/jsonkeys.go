@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// remapRecordKeys streams the JSON records read from r with the hostKey and techKey fields renamed to host and
+// tech, for --json-host-key/--json-tech-key, so the output of fingerprint tools with another schema (e.g.
+// {"url":..., "technologies":[...]}) can be scanned without reformatting. Records without the configured key keep
+// their own host/tech. The tech value may be a list of names, a list of objects with a name (and version), an
+// object keyed by tech name or a comma-separated string.
+func remapRecordKeys(r io.Reader, hostKey, techKey string) io.Reader {
+	if (hostKey == "" || hostKey == "host") && (techKey == "" || techKey == "tech") {
+		return r
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		decoder := json.NewDecoder(r)
+		out := bufio.NewWriter(pw)
+		for {
+			var record map[string]json.RawMessage
+			if err := decoder.Decode(&record); err == io.EOF {
+				break
+			} else if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			if err := remapRecord(record, hostKey, techKey); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			line, _ := json.Marshal(record)
+			out.Write(line)
+			out.WriteByte('\n')
+			// Hand each record on right away so streamed input is scanned as it arrives
+			if err := out.Flush(); err != nil {
+				return
+			}
+		}
+		pw.Close()
+	}()
+	return pr
+}
+
+// remapRecord moves the hostKey and techKey fields of record to host and tech
+func remapRecord(record map[string]json.RawMessage, hostKey, techKey string) error {
+	if value, ok := record[hostKey]; ok && hostKey != "" && hostKey != "host" {
+		record["host"] = value
+		delete(record, hostKey)
+	}
+	if value, ok := record[techKey]; ok && techKey != "" && techKey != "tech" {
+		techs, err := techNames(value)
+		if err != nil {
+			return fmt.Errorf("--json-tech-key %s: %s", techKey, err)
+		}
+		record["tech"], _ = json.Marshal(techs)
+		delete(record, techKey)
+	}
+	return nil
+}
+
+// techNames converts the tech field of another schema into the "name" or "name:version" entries of a tech list
+func techNames(value json.RawMessage) ([]string, error) {
+	var names []string
+	if json.Unmarshal(value, &names) == nil {
+		return names, nil
+	}
+
+	var list string
+	if json.Unmarshal(value, &list) == nil {
+		for _, name := range strings.Split(list, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+		return names, nil
+	}
+
+	type namedTech struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+	var objects []namedTech
+	if json.Unmarshal(value, &objects) == nil {
+		for _, tech := range objects {
+			if tech.Name == "" {
+				continue
+			}
+			if tech.Version != "" {
+				names = append(names, tech.Name+":"+tech.Version)
+			} else {
+				names = append(names, tech.Name)
+			}
+		}
+		return names, nil
+	}
+
+	var keyed map[string]json.RawMessage
+	if json.Unmarshal(value, &keyed) == nil {
+		for name := range keyed {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names, nil
+	}
+
+	return nil, fmt.Errorf("unsupported tech value %s", value)
+}
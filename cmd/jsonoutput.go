@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Values of --stdout-format: raw prints the command output lines as they are, jsonl one scanResult object per line
+var stdoutFormats = []string{"raw", "jsonl"}
+
+// scanResult is one output line written to --output as JSON with --output-json-pretty, or to stdout with
+// --stdout-format jsonl
+type scanResult struct {
+	Host   string `json:"host"`
+	Tech   string `json:"tech"`
+	Output string `json:"output"`
+	Cmd    int    `json:"cmd,omitempty"` // number of the --cmd the line came from, when several are given
+}
+
+// formatPrettyResult renders an output line as an indented JSON object followed by a newline
+func formatPrettyResult(host, tech, line string) string {
+	data, _ := json.MarshalIndent(scanResult{Host: host, Tech: tech, Output: line}, "", "  ")
+	return string(data) + "\n"
+}
+
+// formatResultLine renders an output line of the cmdNumber-th --cmd (0 with a single one) as a single-line JSON
+// object, for --stdout-format jsonl
+func formatResultLine(host, tech, line string, cmdNumber int) string {
+	data, _ := json.Marshal(scanResult{Host: host, Tech: tech, Output: line, Cmd: cmdNumber})
+	return string(data)
+}
+
+// resultHost picks the host a line belongs to: the only host of the job, or for a --group-by-tech batch
+// the first host the line mentions, falling back to label
+func resultHost(line string, hosts []string, label string) string {
+	if len(hosts) == 1 {
+		return hosts[0]
+	}
+	for _, host := range hosts {
+		if strings.Contains(line, host) {
+			return host
+		}
+	}
+	return label
+}
+
+// findingIndex counts the lines written to --output per host
+type findingIndex struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func newFindingIndex() *findingIndex {
+	return &findingIndex{counts: make(map[string]int)}
+}
+
+// add counts one written line for host
+func (f *findingIndex) add(host string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.counts[host]++
+}
+
+// write saves the host -> count map as JSON to path
+func (f *findingIndex) write(path string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, err := json.MarshalIndent(f.counts, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// indexPath returns the index file written next to output, e.g. "nuclei-output.json" -> "nuclei-output-index.json"
+func indexPath(output string) string {
+	return strings.TrimSuffix(output, filepath.Ext(output)) + "-index.json"
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseKeyValueList parses a comma-separated "key=value,key=value" list, trimming keys and lowercasing them
+// unless caseSensitive (--case-sensitive, for lists keyed by tech name)
+func parseKeyValueList(input string, caseSensitive bool) (map[string]string, error) {
+	pairs := make(map[string]string)
+	if strings.TrimSpace(input) == "" {
+		return pairs, nil
+	}
+
+	for _, entry := range strings.Split(input, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid entry %q (expected key=value)", entry)
+		}
+		pairs[techCase(strings.TrimSpace(parts[0]), caseSensitive)] = strings.TrimSpace(parts[1])
+	}
+	return pairs, nil
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// childLimits de-prioritizes and caps the scan commands so heavy parallel runs don't starve the box: --nice
+// lowers the priority of each job's process group, and --max-memory/--max-cpu-time are applied with ulimit at
+// the start of the job's shell, so every program it starts inherits them.
+// All methods are no-ops on a nil *childLimits so callers don't need to check whether any limit is set.
+type childLimits struct {
+	nice    int
+	memory  int64         // address space per process in bytes, 0 for no limit
+	cpuTime time.Duration // CPU time per process, 0 for no limit
+}
+
+// newChildLimits validates the limits and returns nil when none is set
+func newChildLimits(nice int, memory int64, cpuTime time.Duration) (*childLimits, error) {
+	if nice < 0 || nice > 19 {
+		return nil, fmt.Errorf("--nice must be between 0 and 19, got %d", nice)
+	}
+	if cpuTime < 0 {
+		return nil, fmt.Errorf("--max-cpu-time can't be negative")
+	}
+	if cpuTime > 0 && cpuTime < time.Second {
+		return nil, fmt.Errorf("--max-cpu-time must be at least 1s, got %s", cpuTime)
+	}
+	if nice == 0 && memory == 0 && cpuTime == 0 {
+		return nil, nil
+	}
+	return &childLimits{nice: nice, memory: memory, cpuTime: cpuTime}, nil
+}
+
+// wrap prefixes the shell command with the ulimit calls for --max-memory and --max-cpu-time
+func (l *childLimits) wrap(cmdStr string) string {
+	if l == nil {
+		return cmdStr
+	}
+	// One limit per ulimit call, as dash doesn't take several
+	var calls []string
+	if l.memory > 0 {
+		calls = append(calls, fmt.Sprintf("ulimit -v %d", (l.memory+1023)/1024))
+	}
+	if l.cpuTime > 0 {
+		calls = append(calls, fmt.Sprintf("ulimit -t %d", int64(l.cpuTime/time.Second)))
+	}
+	if len(calls) == 0 {
+		return cmdStr
+	}
+	return strings.Join(calls, " && ") + " || exit 1\n" + cmdStr
+}
+
+// prepare puts the child in its own process group before it starts, so started can renice all of it
+func (l *childLimits) prepare(cmd *exec.Cmd) {
+	if l == nil || l.nice == 0 {
+		return
+	}
+	setProcessGroup(cmd)
+}
+
+// started lowers the priority of the started child's process group to --nice. Programs the shell forks later
+// inherit the priority; ones it already forked are in the group and reniced along with it.
+func (l *childLimits) started(cmd *exec.Cmd) error {
+	if l == nil || l.nice == 0 {
+		return nil
+	}
+	return reniceProcessGroup(cmd.Process.Pid, l.nice)
+}
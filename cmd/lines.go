@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bufio"
+	"io"
+	"sync"
+)
+
+// lineMerger reads lines from several pipes at once, e.g. a job's stdout and stderr, and returns them in the
+// order they arrive. Reading the pipes one after the other would print all of stdout before any of stderr, and
+// block the job once it fills the unread stderr pipe.
+type lineMerger struct {
+	lines chan string
+	done  chan struct{}
+	once  sync.Once
+	line  string
+}
+
+// mergeLines starts reading readers line by line
+func mergeLines(readers ...io.Reader) *lineMerger {
+	m := &lineMerger{lines: make(chan string), done: make(chan struct{})}
+	var wg sync.WaitGroup
+	for _, r := range readers {
+		wg.Add(1)
+		go func(r io.Reader) {
+			defer wg.Done()
+			scanner := bufio.NewScanner(r)
+			for scanner.Scan() {
+				select {
+				case m.lines <- scanner.Text():
+				case <-m.done:
+					return
+				}
+			}
+		}(r)
+	}
+	go func() {
+		wg.Wait()
+		close(m.lines)
+	}()
+	return m
+}
+
+// Scan waits for the next line of any reader, and returns false once all of them are exhausted
+func (m *lineMerger) Scan() bool {
+	line, ok := <-m.lines
+	m.line = line
+	return ok
+}
+
+// Text returns the line read by the last Scan
+func (m *lineMerger) Text() string {
+	return m.line
+}
+
+// stop abandons the remaining lines, for callers that break out of the Scan loop early
+func (m *lineMerger) stop() {
+	m.once.Do(func() { close(m.done) })
+}
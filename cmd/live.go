@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"net"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// liveHosts runs the probe command with the hosts on stdin and returns the hosts it reported as alive, giving
+// the probe at most timeout (0 for no limit) and stopping it with ctx. A single host is alive if the probe printed
+// anything; in a batch a host is alive if an output line starts with it, e.g. "https://host:443 [200]".
+// The probe counts against --max-procs; once that is reached no host is alive, as no scan could start anyway.
+func liveHosts(ctx context.Context, probe string, hosts []string, timeout time.Duration, procs *procBudget) []string {
+	if !procs.take() {
+		return nil
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	cmd := exec.CommandContext(ctx, "sh", "-c", probe)
+	killProcessGroupOnCancel(cmd)
+	cmd.Stdin = strings.NewReader(strings.Join(hosts, "\n"))
+	out, err := cmd.Output()
+	if err != nil && len(out) == 0 {
+		return nil
+	}
+
+	output := strings.TrimSpace(string(out))
+	if output == "" {
+		return nil
+	}
+	if len(hosts) == 1 {
+		return hosts
+	}
+
+	reported := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			reported[probeHostName(fields[0])] = true
+		}
+	}
+	var alive []string
+	for _, host := range hosts {
+		if reported[probeHostName(host)] {
+			alive = append(alive, host)
+		}
+	}
+	return alive
+}
+
+// probeHostName reduces a host or URL to its lowercase name without scheme, port or path, so a probe's
+// "https://Example.com:443/" matches the input host example.com but not sub.example.com
+func probeHostName(host string) string {
+	host = strings.ToLower(host)
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	if i := strings.IndexAny(host, "/?#"); i >= 0 {
+		host = host[:i]
+	}
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		host = name
+	}
+	return strings.TrimSuffix(host, ".")
+}
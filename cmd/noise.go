@@ -0,0 +1,62 @@
+package cmd
+
+import "sort"
+
+// noiseTechs are low-signal techs excluded by --exclude-noise: CDNs, analytics and tag managers, and common
+// front-end libraries that are on most hosts and rarely have templates worth running. Names are normalized
+// (lowercase, as techfinder reports them); techs with spaces are skipped anyway, so only their hyphenated
+// forms are listed.
+var noiseTechs = map[string]bool{
+	// CDNs and edge networks
+	"akamai":            true,
+	"amazon-cloudfront": true,
+	"cloudflare":        true,
+	"fastly":            true,
+	"jsdelivr":          true,
+	"cdnjs":             true,
+	"unpkg":             true,
+
+	// Analytics, tag managers and ad trackers
+	"google-analytics":        true,
+	"google-tag-manager":      true,
+	"google-adsense":          true,
+	"facebook-pixel":          true,
+	"hotjar":                  true,
+	"segment":                 true,
+	"matomo-analytics":        true,
+	"microsoft-clarity":       true,
+	"linkedin-insight-tag":    true,
+	"hubspot-analytics":       true,
+	"google-font-api":         true,
+	"google-hosted-libraries": true,
+
+	// Front-end libraries and frameworks
+	"jquery":         true,
+	"jquery-ui":      true,
+	"jquery-migrate": true,
+	"bootstrap":      true,
+	"font-awesome":   true,
+	"lodash":         true,
+	"underscore.js":  true,
+	"moment.js":      true,
+	"core-js":        true,
+	"modernizr":      true,
+	"popper":         true,
+	"swiper":         true,
+	"slick":          true,
+	"recaptcha":      true,
+	"lazysizes":      true,
+}
+
+// noiseExcludes returns the --exclude-noise techs to add to the exclude list, sorted, leaving out those the
+// user asked for explicitly with --include-tech
+func noiseExcludes(include []string) []string {
+	var techs []string
+	for tech := range noiseTechs {
+		if !contains(include, tech) {
+			techs = append(techs, tech)
+		}
+	}
+	sort.Strings(techs)
+	return techs
+}
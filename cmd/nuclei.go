@@ -2,22 +2,28 @@ package cmd
 
 import (
   "bufio"
+  "context"
   "encoding/json"
+  "errors"
   "fmt"
   "io"
-  "io/ioutil"
   "os"
   "os/exec"
+  "sort"
   "strings"
   "sync"
+  "sync/atomic"
+  "time"
 
   "github.com/spf13/cobra"
+  "golang.org/x/sync/semaphore"
 )
 
 // Structure to map the JSON data
 type TechData struct {
-  Host string   `json:"host"`
-  Tech []string `json:"tech"`
+  Host string          `json:"host"`
+  Tech []string        `json:"tech"`
+  raw  json.RawMessage // the whole input record, for the other fields substituted by --json-fields
 }
 
 // nucleiCmd represents the nuclei command
@@ -34,39 +40,412 @@ Examples:
   cat techfinder-output.json | vulntechfinder nuclei --cmd "nuclei -duc -t ~/nuclei-templates -tags {tech} -es unknown,info,low" --parallel 10 --output nuclei-output.txt
 `,
   Run: func(cmd *cobra.Command, args []string) {
-    nucleiCmdStr, _ := cmd.Flags().GetString("cmd")
+    nucleiCmds, _ := cmd.Flags().GetStringArray("cmd")
     verbose, _ := cmd.Flags().GetBool("verbose")
     process, _ := cmd.Flags().GetBool("process")
     parallel, _ := cmd.Flags().GetInt("parallel")
     Output, _ := cmd.Flags().GetString("output")
     excludeTech, _ := cmd.Flags().GetString("exclude-tech")
     includeTech, _ := cmd.Flags().GetString("include-tech")
+    idleWarn, _ := cmd.Flags().GetDuration("idle-warn")
+    extraArgs, _ := cmd.Flags().GetString("extra-args")
+    splitBySeverity, _ := cmd.Flags().GetBool("split-output-by-severity")
+    hostMode, _ := cmd.Flags().GetString("host-mode")
+    limit, _ := cmd.Flags().GetInt("limit")
+    insecure, _ := cmd.Flags().GetBool("insecure")
+    timings, _ := cmd.Flags().GetBool("timings")
+    hostRewriteRules, _ := cmd.Flags().GetStringArray("host-rewrite")
+    templatesDir, _ := cmd.Flags().GetString("templates-dir")
+    resumeFile, _ := cmd.Flags().GetString("resume")
+    resumeGranularity, _ := cmd.Flags().GetString("resume-granularity")
+    seenDBFile, _ := cmd.Flags().GetString("seen-db")
+    skipSeenWithin, _ := cmd.Flags().GetDuration("skip-seen-within")
+    parseFindings, _ := cmd.Flags().GetString("parse-findings")
+    globalTimeout, _ := cmd.Flags().GetDuration("timeout")
+    maxJobLines, _ := cmd.Flags().GetInt("max-output-lines-per-job")
+    nice, _ := cmd.Flags().GetInt("nice")
+    maxMemoryStr, _ := cmd.Flags().GetString("max-memory")
+    maxCPUTime, _ := cmd.Flags().GetDuration("max-cpu-time")
+    timeoutMapStr, _ := cmd.Flags().GetString("timeout-map")
+    onlyLive, _ := cmd.Flags().GetBool("only-live")
+    liveProbe, _ := cmd.Flags().GetString("live-probe")
+    liveProbeTimeout, _ := cmd.Flags().GetDuration("live-probe-timeout")
+    jsonFields, _ := cmd.Flags().GetBool("json-fields")
+    sequential, _ := cmd.Flags().GetBool("sequential")
+    envFile, _ := cmd.Flags().GetString("env-file")
+    concurrencyAuto, _ := cmd.Flags().GetBool("concurrency-auto")
+    dedupOutput, _ := cmd.Flags().GetBool("dedup-output")
+    inputFormat, _ := cmd.Flags().GetString("input-format")
+    csvHostColumn, _ := cmd.Flags().GetInt("csv-host-column")
+    csvTechColumn, _ := cmd.Flags().GetInt("csv-tech-column")
+    csvTechSeparator, _ := cmd.Flags().GetString("csv-tech-separator")
+    firstMatch, _ := cmd.Flags().GetBool("first-match")
+    minSeverity, _ := cmd.Flags().GetString("min-severity")
+    quietOutput, _ := cmd.Flags().GetBool("quiet-output")
+    outputMaxSizeStr, _ := cmd.Flags().GetString("output-max-size")
+    preCmd, _ := cmd.Flags().GetString("pre-cmd")
+    commandPrefix, _ := cmd.Flags().GetString("command-prefix")
+    postCmd, _ := cmd.Flags().GetString("post-cmd")
+    skipOnPreCmdFail, _ := cmd.Flags().GetBool("skip-on-pre-cmd-fail")
+    versionFilters, _ := cmd.Flags().GetStringArray("tech-version-filter")
+    workdir, _ := cmd.Flags().GetString("workdir")
+    hostComment, _ := cmd.Flags().GetBool("output-append-host-comment")
+    maxRuntime, _ := cmd.Flags().GetDuration("max-runtime")
+    requireAllTech, _ := cmd.Flags().GetString("require-all-tech")
+    jsonPretty, _ := cmd.Flags().GetBool("output-json-pretty")
+    validateOutput, _ := cmd.Flags().GetBool("validate-output")
+    randomUA, _ := cmd.Flags().GetBool("random-ua")
+    uaFile, _ := cmd.Flags().GetString("ua-file")
+    cmdFile, _ := cmd.Flags().GetString("cmd-file")
+    normalizeHosts, _ := cmd.Flags().GetBool("normalize-host")
+    techWeightStr, _ := cmd.Flags().GetString("tech-weight")
+    samplePerTech, _ := cmd.Flags().GetInt("sample-per-tech")
+    fastDecode, _ := cmd.Flags().GetBool("fast-decode")
+    techMapOutput, _ := cmd.Flags().GetString("tech-map-output")
+    includeCommand, _ := cmd.Flags().GetBool("output-include-command")
+    techPrefixMatch, _ := cmd.Flags().GetBool("tech-prefix-match")
+    templateVarFlags, _ := cmd.Flags().GetStringArray("var")
+    maxProcs, _ := cmd.Flags().GetInt("max-procs")
+    printPlan, _ := cmd.Flags().GetBool("print-plan")
+    inputPath, _ := cmd.Flags().GetString("input")
+    validateOnly, _ := cmd.Flags().GetBool("validate-only")
+    abortOnFirstError, _ := cmd.Flags().GetBool("abort-on-first-error")
+    excludeNoise, _ := cmd.Flags().GetBool("exclude-noise")
+    shutdownGrace, _ := cmd.Flags().GetDuration("shutdown-grace")
+    expandCIDR, _ := cmd.Flags().GetBool("expand-cidr")
+    expandCIDRMax, _ := cmd.Flags().GetInt("expand-cidr-max")
+    orderedOutputFlag, _ := cmd.Flags().GetBool("ordered-output")
+    orderedBufferStr, _ := cmd.Flags().GetString("ordered-buffer")
+    strictJSON, _ := cmd.Flags().GetBool("strict-json")
+    batchSize, _ := cmd.Flags().GetInt("batch-size")
+    symlinkLatest, _ := cmd.Flags().GetBool("output-symlink-latest")
+    outputDaily, _ := cmd.Flags().GetBool("output-daily")
+    refingerprintNull, _ := cmd.Flags().GetBool("refingerprint-null")
+    continueOnTechfinderError, _ := cmd.Flags().GetBool("continue-on-techfinder-error")
+    onlyReportHits, _ := cmd.Flags().GetBool("only-report-hits")
+    techSegment, _ := cmd.Flags().GetString("tech-segment")
+    caseSensitive, _ := cmd.Flags().GetBool("case-sensitive")
+    unsafeTechPolicy, _ := cmd.Flags().GetString("unsafe-tech-policy")
+    unsafeTechChars, _ := cmd.Flags().GetString("unsafe-tech-chars")
+    errorThreshold, _ := cmd.Flags().GetFloat64("error-threshold")
+    errorBackoff, _ := cmd.Flags().GetDuration("error-backoff")
+    sortTechs, _ := cmd.Flags().GetBool("sort-techs")
+    tcMaxLength, _ := cmd.Flags().GetInt("tc-max-length")
+    failOnFindings, _ := cmd.Flags().GetBool("fail-on-findings")
+    onFindingExec, _ := cmd.Flags().GetString("on-finding-exec")
+    onFindingRate, _ := cmd.Flags().GetInt("on-finding-rate")
+    stdoutOnlyFindings, _ := cmd.Flags().GetBool("output-stdout-only-findings")
+    stdoutFormat, _ := cmd.Flags().GetString("stdout-format")
+    silent, _ := cmd.Flags().GetBool("silent")
+    summaryJSON, _ := cmd.Flags().GetBool("summary-json")
+    sampleRandom, _ := cmd.Flags().GetBool("sample-random")
+    jsonHostKey, _ := cmd.Flags().GetString("json-host-key")
+    jsonTechKey, _ := cmd.Flags().GetString("json-tech-key")
+    rareFirst, _ := cmd.Flags().GetBool("rare-first")
+    insecureFlagsMap, _ := cmd.Flags().GetString("insecure-flags")
+    groupByTech, _ := cmd.Flags().GetBool("group-by-tech")
 
-    if nucleiCmdStr == "" {
-      fmt.Println("Usage: vulntechfinder nuclei --cmd <nuclei command> [--parallel N] [--output file]")
+    if cmdFile != "" {
+      if len(nucleiCmds) > 0 {
+        fmt.Println("Error: --cmd and --cmd-file can't be used together")
+        os.Exit(1)
+      }
+      fileCmd, err := readCmdFile(cmdFile)
+      if err != nil {
+        fmt.Printf("Error reading --cmd-file: %s\n", err)
+        os.Exit(1)
+      }
+      nucleiCmds = []string{fileCmd}
+    }
+
+    if len(nucleiCmds) == 0 || contains(nucleiCmds, "") {
+      fmt.Println("Usage: vulntechfinder nuclei --cmd <nuclei command> | --cmd-file <file> [--parallel N] [--output file]")
       os.Exit(1)
     }
 
+    // Fill the per-run {name} placeholders given with --var
+    templateVars, err := parseTemplateVars(templateVarFlags)
+    if err != nil {
+      fmt.Printf("Error: %s\n", err)
+      os.Exit(1)
+    }
+    for i := range nucleiCmds {
+      nucleiCmds[i] = applyTemplateVars(nucleiCmds[i], templateVars)
+    }
+
+    // Refuse runs nested in their own jobs and warn about templates that look like they recurse
+    depthEnv, err := checkNesting()
+    if err != nil {
+      fmt.Printf("Error: %s\n", err)
+      os.Exit(1)
+    }
+    for _, nucleiCmdStr := range nucleiCmds {
+      for _, warning := range recursionWarnings(nucleiCmdStr) {
+        fmt.Printf("WARNING: %s; consider capping it with --max-procs\n", warning)
+      }
+    }
+    procs := &procBudget{max: int64(maxProcs)}
+
     if parallel <= 0 {
       parallel = 50
     }
 
+    // Size the pool from the CPU count unless --parallel was given explicitly
+    if concurrencyAuto && !cmd.Flags().Changed("parallel") {
+      parallel = autoConcurrency()
+      fmt.Printf("Using %d parallel processes (--concurrency-auto)\n", parallel)
+    }
+
+    // Run one job at a time in input order so output is reproducible
+    if sequential {
+      parallel = 1
+    }
+
+    // Append --extra-args to the template so the -tc/-tags detection and {tech} replacement also see them
+    if extraArgs = strings.TrimSpace(extraArgs); extraArgs != "" {
+      for i := range nucleiCmds {
+        nucleiCmds[i] = nucleiCmds[i] + " " + extraArgs
+      }
+    }
+
+    // Append the tool's skip-verify flag for --insecure
+    if insecure {
+      overrides, err := parseKeyValueList(insecureFlagsMap, false)
+      if err != nil {
+        fmt.Printf("Error parsing --insecure-flags: %s\n", err)
+        os.Exit(1)
+      }
+      for i := range nucleiCmds {
+        flag, ok := insecureFlagFor(nucleiCmds[i], "nuclei", overrides)
+        if !ok {
+          fmt.Printf("Error: --insecure has no skip-verify flag for --cmd %q (nuclei and httpx skip certificate checks by default); set one with --insecure-flags tool=flag\n", nucleiCmds[i])
+          os.Exit(1)
+        }
+        nucleiCmds[i] = nucleiCmds[i] + " " + flag
+      }
+    }
+
+    for _, nucleiCmdStr := range nucleiCmds {
+      if err := validateHostMode(hostMode, nucleiCmdStr); err != nil {
+        fmt.Printf("Error: %s\n", err)
+        os.Exit(1)
+      }
+    }
+
+    // All templates of a repeated --cmd, for the checks of features that apply when any of them uses one
+    nucleiCmdStr := strings.Join(nucleiCmds, " ; ")
+
+    if strings.Contains(nucleiCmdStr, placeholderFile) && (batchSize > 0 || groupByTech) {
+      fmt.Println("Error: {file:<glob>} is resolved per host and can't be used with --batch-size or --group-by-tech")
+      os.Exit(1)
+    }
+
+    if batchSize > 0 && (hostMode != "stdin" || groupByTech || firstMatch) {
+      fmt.Println("Error: --batch-size feeds a batch of hosts on stdin and requires --host-mode stdin (not with --group-by-tech or --first-match)")
+      os.Exit(1)
+    }
+
+    if tcMaxLength < 0 {
+      fmt.Println("Error: --tc-max-length must be 0 (no limit) or a positive number of bytes")
+      os.Exit(1)
+    }
+
+    if groupByTech && hostMode != "stdin" {
+      fmt.Println("Error: --group-by-tech feeds all hosts of a tech on stdin and requires --host-mode stdin")
+      os.Exit(1)
+    }
+
+    if groupByTech && firstMatch {
+      fmt.Println("Error: --first-match stops a host's scan after its first finding and can't be used with --group-by-tech")
+      os.Exit(1)
+    }
+
     // Parse exclude and include lists (support both comma-separated and file paths)
-    excludeList, err := parseTechInput(excludeTech)
+    excludeList, err := parseTechInput(excludeTech, caseSensitive)
     if err != nil {
       fmt.Printf("Error reading exclude-tech input: %s\n", err)
       os.Exit(1)
     }
 
-    includeList, err := parseTechInput(includeTech)
+    includeList, err := parseTechInput(includeTech, caseSensitive)
     if err != nil {
       fmt.Printf("Error reading include-tech input: %s\n", err)
       os.Exit(1)
     }
 
-    // Validate that both exclude and include are not used together
-    if len(excludeList) > 0 && len(includeList) > 0 {
-      fmt.Println("Error: Cannot use both --exclude-tech and --include-tech flags together")
+    // Merge the built-in --exclude-noise list into the exclude list, except for techs included explicitly
+    if excludeNoise {
+      excludeList = append(excludeList, noiseExcludes(includeList)...)
+    }
+
+    requiredTechs, err := parseTechInput(requireAllTech, caseSensitive)
+    if err != nil {
+      fmt.Printf("Error reading require-all-tech input: %s\n", err)
+      os.Exit(1)
+    }
+
+    hostRewrites, err := parseHostRewrites(hostRewriteRules)
+    if err != nil {
+      fmt.Printf("Error parsing --host-rewrite: %s\n", err)
+      os.Exit(1)
+    }
+
+    versionConstraints, err := parseVersionConstraints(versionFilters)
+    if err != nil {
+      fmt.Printf("Error parsing --tech-version-filter: %s\n", err)
+      os.Exit(1)
+    }
+
+    timeoutMap, err := parseTimeoutMap(timeoutMapStr, caseSensitive)
+    if err != nil {
+      fmt.Printf("Error parsing --timeout-map: %s\n", err)
+      os.Exit(1)
+    }
+
+    techAliases, err := parseTechMap(techMapOutput)
+    if err != nil {
+      fmt.Printf("Error reading --tech-map-output: %s\n", err)
+      os.Exit(1)
+    }
+
+    techWeights, err := parseTechWeights(techWeightStr, caseSensitive)
+    if err != nil {
+      fmt.Printf("Error parsing --tech-weight: %s\n", err)
+      os.Exit(1)
+    }
+
+    childEnv, err := loadEnvFile(envFile)
+    if err != nil {
+      fmt.Printf("Error reading --env-file: %s\n", err)
+      os.Exit(1)
+    }
+    childEnv = append(childEnv, depthEnv)
+
+    // --ua-file implies --random-ua
+    var userAgents []string
+    if randomUA || uaFile != "" {
+      userAgents, err = loadUserAgents(uaFile)
+      if err != nil {
+        fmt.Printf("Error reading --ua-file: %s\n", err)
+        os.Exit(1)
+      }
+    }
+
+    // Findings ranked below --min-severity are dropped; rank 0 (unknown) keeps everything
+    minSeverityRank := 0
+    if minSeverity != "" {
+      minSeverityRank = severityRank(minSeverity)
+      if minSeverityRank < 0 {
+        fmt.Printf("Error: invalid --min-severity %q (expected one of: %s)\n", minSeverity, strings.Join(nucleiSeverities, ", "))
+        os.Exit(1)
+      }
+    }
+
+    if quietOutput && Output == "" && !splitBySeverity {
+      fmt.Println("Error: --quiet-output suppresses terminal output and needs --output or --split-output-by-severity")
+      os.Exit(1)
+    }
+
+    outputMaxSize, err := parseSize(outputMaxSizeStr)
+    if err != nil {
+      fmt.Printf("Error parsing --output-max-size: %s\n", err)
+      os.Exit(1)
+    }
+
+    // De-prioritize and cap the scan commands with --nice, --max-memory and --max-cpu-time
+    maxMemory, err := parseSize(maxMemoryStr)
+    if err != nil {
+      fmt.Printf("Error parsing --max-memory: %s\n", err)
+      os.Exit(1)
+    }
+    limits, err := newChildLimits(nice, maxMemory, maxCPUTime)
+    if err != nil {
+      fmt.Printf("Error: %s\n", err)
+      os.Exit(1)
+    }
+
+    orderedBuffer, err := parseSize(orderedBufferStr)
+    if err != nil {
+      fmt.Printf("Error parsing --ordered-buffer: %s\n", err)
+      os.Exit(1)
+    }
+
+    if workdir != "" {
+      if info, err := os.Stat(workdir); err != nil || !info.IsDir() {
+        fmt.Printf("Error: --workdir %q is not a directory\n", workdir)
+        os.Exit(1)
+      }
+    }
+
+    if outputDaily && Output == "" {
+      fmt.Println("Error: --output-daily needs --output")
+      os.Exit(1)
+    }
+
+    if validateOutput && (Output == "" || !(jsonPretty || parseFindings == "json") || hostComment || compressedOutput(Output)) {
+      fmt.Println("Error: --validate-output checks JSON output and needs --output with --output-json-pretty or --parse-findings json, without --output-append-host-comment or a compressed .zst path")
+      os.Exit(1)
+    }
+
+    if symlinkLatest && !strings.Contains(Output, outputTimestamp) {
+      fmt.Println("Error: --output-symlink-latest needs an --output path with {timestamp}, e.g. results/scan-{timestamp}.txt")
+      os.Exit(1)
+    }
+
+    if parseFindings != "" && !contains(findingFormats, parseFindings) {
+      fmt.Printf("Error: invalid --parse-findings %q (expected one of: %s)\n", parseFindings, strings.Join(findingFormats, ", "))
+      os.Exit(1)
+    }
+
+    if parseFindings != "" && (Output == "" || jsonPretty || hostComment || (parseFindings == "csv" && includeCommand)) {
+      fmt.Println("Error: --parse-findings needs --output and can't be combined with --output-json-pretty, --output-append-host-comment or (for csv) --output-include-command")
+      os.Exit(1)
+    }
+
+    if jsonPretty && (Output == "" || hostComment) {
+      fmt.Println("Error: --output-json-pretty needs --output and can't be combined with --output-append-host-comment")
+      os.Exit(1)
+    }
+
+    if strictJSON && fastDecode {
+      fmt.Println("Error: --strict-json and --fast-decode can't be used together")
+      os.Exit(1)
+    }
+
+    if sampleRandom && samplePerTech <= 0 {
+      fmt.Println("Error: --sample-random picks the --sample-per-tech hosts and needs --sample-per-tech")
+      os.Exit(1)
+    }
+
+    if !contains(inputFormats, inputFormat) {
+      fmt.Printf("Error: invalid --input-format %q (expected one of: %s)\n", inputFormat, strings.Join(inputFormats, ", "))
+      os.Exit(1)
+    }
+
+    if !contains(stdoutFormats, stdoutFormat) {
+      fmt.Printf("Error: invalid --stdout-format %q (expected one of: %s)\n", stdoutFormat, strings.Join(stdoutFormats, ", "))
+      os.Exit(1)
+    }
+
+    if !contains(resumeGranularities, resumeGranularity) {
+      fmt.Printf("Error: invalid --resume-granularity %q (expected one of: %s)\n", resumeGranularity, strings.Join(resumeGranularities, ", "))
+      os.Exit(1)
+    }
+
+    if !contains(unsafeTechPolicies, unsafeTechPolicy) {
+      fmt.Printf("Error: invalid --unsafe-tech-policy %q (expected one of: %s)\n", unsafeTechPolicy, strings.Join(unsafeTechPolicies, ", "))
+      os.Exit(1)
+    }
+
+    if !contains(techSegments, techSegment) {
+      fmt.Printf("Error: invalid --tech-segment %q (expected one of: %s)\n", techSegment, strings.Join(techSegments, ", "))
+      os.Exit(1)
+    }
+
+    // Validate the combination of tech filter flags
+    filters := techFilters{include: includeList, exclude: excludeList, requireAll: requiredTechs, versions: versionConstraints, prefix: techPrefixMatch}
+    if err := filters.validate(); err != nil {
+      fmt.Printf("Error: %s\n", err)
       os.Exit(1)
     }
 
@@ -79,36 +458,79 @@ Examples:
       }
     }
 
-    // Read all stdin
-    stdinBytes, err := ioutil.ReadAll(os.Stdin)
-    if err != nil {
-      fmt.Printf("Error reading stdin: %s\n", err)
-      os.Exit(1)
+    // With --validate-only, stop once the configuration is checked, without reading input or scanning
+    if validateOnly {
+      dailyBase := Output
+      if outputDaily {
+        dailyBase = "" // the day directories are created as needed
+      }
+      var problems []string
+      for _, template := range nucleiCmds {
+        problems = append(problems, validateRun(commandPrefix, template, inputPath, map[string]string{"output": dailyBase, "resume": resumeFile, "seen-db": seenDBFile})...)
+      }
+      reportValidation(problems)
+      return
     }
 
-    if len(stdinBytes) == 0 {
-      fmt.Println("No input provided on stdin. Provide JSON or pipe host list into this command.")
-      os.Exit(1)
+    // Read from --input (a file, named pipe or Unix socket) or stdin
+    var input io.Reader = os.Stdin
+    if inputPath != "" {
+      inputFile, err := openInput(inputPath)
+      if err != nil {
+        fmt.Printf("Error opening --input: %s\n", err)
+        os.Exit(1)
+      }
+      defer inputFile.Close()
+      input = inputFile
     }
 
-    trimmed := strings.TrimSpace(string(stdinBytes))
+    // Drop a BOM or stray control bytes some producers emit before the data, and detect if the input already
+    // contains JSON (its first value parses as JSON). JSON is decoded as it streams in; other input is read whole first.
+    buffered := bufio.NewReaderSize(input, sniffSize)
+    streamJSON := looksLikeJSON(buffered) && inputFormat == "auto"
+
+    var stdinBytes []byte
+    if !streamJSON {
+      stdinBytes, err = io.ReadAll(buffered)
+      if err != nil {
+        fmt.Printf("Error reading input: %s\n", err)
+        os.Exit(1)
+      }
+
+      if len(stdinBytes) == 0 {
+        fmt.Println("No input provided on stdin. Provide JSON or pipe host list into this command.")
+        os.Exit(1)
+      }
+    }
 
     var reader io.Reader
 
-    // Detect if stdin already contains JSON (starts with [ or {). If not, run techfinder -silent -json
-    if strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{") {
+    // Convert CSV and line inventories to JSON records, decode JSON directly, and otherwise run techfinder -silent -json
+    if streamJSON {
       if verbose {
         fmt.Println("Detected JSON on stdin — parsing directly.")
       }
-      reader = strings.NewReader(string(stdinBytes))
+      reader = buffered
+    } else if inputFormat == "csv" {
+      converted, err := csvToJSON(stdinBytes, csvHostColumn, csvTechColumn, csvTechSeparator)
+      if err != nil {
+        fmt.Printf("Error parsing CSV input: %s\n", err)
+        os.Exit(1)
+      }
+      reader = strings.NewReader(string(converted))
+    } else if inputFormat == "line" {
+      converted, err := lineToJSON(stdinBytes)
+      if err != nil {
+        fmt.Printf("Error parsing line input: %s\n", err)
+        os.Exit(1)
+      }
+      reader = strings.NewReader(string(converted))
     } else {
       if verbose {
         fmt.Println("No JSON detected on stdin — running 'techfinder -silent -json' and piping stdin to it.")
       }
       // Run techfinder -silent -json, feeding stdinBytes into its stdin, and capture stdout
-      techfinderCmd := exec.Command("sh", "-c", "techfinder -silent -json")
-      techfinderCmd.Stdin = strings.NewReader(string(stdinBytes))
-      out, err := techfinderCmd.Output()
+      out, err := runTechfinder(stdinBytes, continueOnTechfinderError)
       if err != nil {
         fmt.Printf("Error running techfinder: %s\n", err)
         os.Exit(1)
@@ -116,102 +538,317 @@ Examples:
       reader = strings.NewReader(string(out))
     }
 
-    // Open the output file for appending if the --output flag is specified
-    var outputFile *os.File
+    // Open the output file for appending if the --output flag is specified, rotating it past --output-max-size
+    var outputFile *outputWriter
+    Output = expandOutputPath(Output, time.Now())
     if Output != "" {
-      outputFile, err = os.OpenFile(Output, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+      outputFile, err = openOutputWriter(Output, outputMaxSize, outputDaily)
       if err != nil {
         fmt.Printf("Error opening output file: %s\n", err)
         os.Exit(1)
       }
       defer outputFile.Close()
+      if validateOutput {
+        outputFile.validateJSON()
+      }
+
+      // Start every --parse-findings csv file, including rotated and daily ones, with its header
+      if parseFindings == "csv" {
+        if err := outputFile.setHeader(formatFindingCSVHeader()); err != nil {
+          fmt.Printf("Error writing to output file: %s\n", err)
+          os.Exit(1)
+        }
+      }
+
+      // Record how this run was started with --output-include-command
+      if includeCommand {
+        if _, err := outputFile.WriteString(outputHeader(nucleiCmdStr, jsonPretty || parseFindings == "json")); err != nil {
+          fmt.Printf("Error writing to output file: %s\n", err)
+          os.Exit(1)
+        }
+      }
     }
 
-    decoder := json.NewDecoder(reader)
-    var wg sync.WaitGroup
-    semaphore := make(chan struct{}, parallel) // Limit the number of parallel executions
+    // Count written lines per host for the --output-json-pretty index file
+    var resultIndex *findingIndex
+    if jsonPretty {
+      resultIndex = newFindingIndex()
+    }
 
-    for {
-      var techData TechData
-      if err := decoder.Decode(&techData); err == io.EOF {
-        break
-      } else if err != nil {
+    // Route findings into one file per severity if --split-output-by-severity is specified
+    var severityOutput *severityFiles
+    if splitBySeverity {
+      severityOutput = newSeverityFiles(Output)
+      defer severityOutput.close()
+    }
+
+    // Skip jobs completed by a previous run and record new completions if --resume is specified
+    var resume *resumeLog
+    if resumeFile != "" {
+      resume, err = openResumeLog(resumeFile, resumeGranularity == "host")
+      if err != nil {
+        fmt.Printf("Error opening resume file: %s\n", err)
+        os.Exit(1)
+      }
+      defer resume.close()
+    }
+
+    // Remember when each host/tech pair was scanned across runs with --seen-db, skipping recent ones with --skip-seen-within
+    var seen *seenDB
+    if seenDBFile != "" {
+      seen, err = openSeenDB(seenDBFile, skipSeenWithin)
+      if err != nil {
+        fmt.Printf("Error opening --seen-db: %s\n", err)
+        os.Exit(1)
+      }
+      defer seen.close()
+    }
+
+    // Suppress findings already written if --dedup-output is specified
+    var deduper *lineDeduper
+    if dedupOutput {
+      deduper = newLineDeduper()
+    }
+
+    // Read the host and tech from the --json-host-key/--json-tech-key fields of other fingerprint tools
+    reader = remapRecordKeys(reader, jsonHostKey, jsonTechKey)
+
+    // With --sample-random the records are shuffled so the --sample-per-tech hosts are picked at random
+    if sampleRandom {
+      reader, err = shuffleRecords(reader)
+      if err != nil {
         fmt.Printf("Error decoding JSON: %s\n", err)
         os.Exit(1)
       }
+    }
 
-      // Skip processing if tech is nil
-      if techData.Tech == nil {
-        if verbose {
-          fmt.Printf("Skipping URL with tech field as null: %s\n", techData.Host)
+    // With --rare-first the records are buffered and ordered so the techs on the fewest hosts are scanned first
+    if rareFirst {
+      reader, err = rareFirstRecords(reader, techSegment, caseSensitive, filters)
+      if err != nil {
+        fmt.Printf("Error decoding JSON: %s\n", err)
+        os.Exit(1)
+      }
+    }
+    sampler := newTechSampler(samplePerTech)
+
+    // --fast-decode unmarshals NDJSON lines on a worker pool so decoding keeps up with fast jobs, and
+    // --strict-json rejects records that don't match the expected schema
+    var decoder recordDecoder = json.NewDecoder(reader)
+    if fastDecode {
+      decoder = newParallelDecoder[TechData](reader)
+    } else if strictJSON {
+      decoder = newStrictDecoder[strictTechRecord](reader)
+    }
+    var wg sync.WaitGroup
+    sem := semaphore.NewWeighted(int64(parallel)) // Limit the number of parallel executions, heavy techs taking several slots per --tech-weight
+
+    // Warn about stalled jobs if nothing completes within --idle-warn, and time the jobs for --timings or --verbose
+    jobs := newActiveJobs(timings || verbose)
+    stopWatch := make(chan struct{})
+    go jobs.watch(idleWarn, stopWatch)
+
+    // Count jobs and findings for the summary and --fail-on-findings
+    tally := newScanTally()
+
+    // kill -USR1 <pid> prints the progress, with the findings so far, to stderr without stopping the run
+    go jobs.dumpStatsOnSignal(stopWatch, tally.summary)
+
+    // Run --on-finding-exec for findings in the background, rate limited
+    findingHooks := newFindingHook(onFindingExec, onFindingRate, workdir, childEnv, procs)
+
+    // Track job failures to pause the scan with --error-threshold
+    breaker := newCircuitBreaker(errorThreshold, errorBackoff)
+
+    // Every job runs under the --max-runtime deadline
+    runCtx, cancelRun := runContext(maxRuntime)
+    defer cancelRun()
+
+    // On Ctrl-C, stop the jobs but keep writing the output they produced for up to --shutdown-grace
+    interrupt := handleInterrupt(cancelRun, shutdownGrace)
+
+    // Stop the scan quietly when the reader of stdout goes away
+    stdout := newStdoutWriter(cancelRun)
+
+    // Stop the scan on the first failed job with --abort-on-first-error
+    abort := newJobAbort(abortOnFirstError, cancelRun)
+
+    // With --ordered-output, the terminal lines of parallel jobs are printed in dispatch order
+    ordered := newOrderedOutput(orderedOutputFlag, stdout.jobLine, orderedBuffer)
+
+    // Batch jobs are numbered in their labels, so batches of the same size and techs are told apart
+    var batches atomic.Int64
+
+    // runJob runs the nuclei template for techs against hosts, filling {field} placeholders from fields;
+    // call it as a goroutine after acquiring jobWeight(techs) slots of the semaphore, with seq from ordered.begin()
+    runJob := func(hosts []string, techs []string, fields map[string]interface{}, seq int) {
+      defer wg.Done()
+      defer sem.Release(jobWeight(techs, techWeights, parallel, caseSensitive)) // Release the semaphore
+      defer ordered.finish(seq)
+
+      // Terminal lines go through --ordered-output when it is on
+      emit := stdout.jobLine
+      if ordered != nil {
+        emit = func(line string) { ordered.println(seq, line) }
+      }
+
+      // The semaphore may have been acquired after --max-runtime was reached
+      if runCtx.Err() != nil {
+        return
+      }
+
+      tech := techCase(strings.Join(techs, ","), caseSensitive)
+
+      // Drop hosts that don't answer the --live-probe before spending nuclei time on them
+      if onlyLive {
+        alive := liveHosts(runCtx, liveProbe, hosts, liveProbeTimeout, procs)
+        if verbose && len(alive) < len(hosts) {
+          fmt.Printf("SKIPPED: %d of %d hosts not live for %s\n", len(hosts)-len(alive), len(hosts), tech)
         }
-        continue
+        if len(alive) == 0 {
+          return
+        }
+        hosts = alive
       }
 
-      wg.Add(1)
-      semaphore <- struct{}{} // Acquire a semaphore
-      go func(techData TechData) {
-        defer wg.Done()
-        defer func() { <-semaphore }() // Release the semaphore
-
-        // Process tech field with include/exclude logic
-        var techs []string
-        for _, t := range techData.Tech {
-          parts := strings.SplitN(t, ":", 2)
-          if len(parts) > 0 {
-            tech := strings.TrimSpace(parts[0])
-            // Ignore technologies with spaces
-            if !strings.Contains(tech, " ") {
-              techLower := strings.ToLower(tech)
-              
-              // If include list is specified, only include technologies in the list
-              if len(includeList) > 0 {
-                if contains(includeList, techLower) {
-                  techs = append(techs, tech)
-                }
-              } else {
-                // Otherwise, use exclude logic only
-                if !contains(excludeList, techLower) {
-                  techs = append(techs, tech)
-                }
-              }
-            }
-          }
+      // Hosts are fed newline separated; the job label names the host, or the number and size of a --batch-size
+      // or --group-by-tech batch
+      hostInput := strings.Join(hosts, "\n")
+      label := hostInput
+      if len(hosts) > 1 {
+        label = fmt.Sprintf("batch %d, %d hosts", batches.Add(1), len(hosts))
+      }
+
+      // Fill {file:<glob>} placeholders for this job, skipping it when a file is missing
+      templates := make([]string, len(nucleiCmds))
+      for i, nucleiCmdStr := range nucleiCmds {
+        template, missingFile, ok := resolveFilePlaceholders(nucleiCmdStr, hostInput, tech)
+        if !ok {
+          fmt.Printf("Skipping %s (%s): no file matches %s\n", label, tech, missingFile)
+          return
+        }
+        templates[i] = template
+      }
+
+      jobKey := fmt.Sprintf("%s (%s)", label, tech)
+      jobID := jobs.start(jobKey)
+      tally.job(hosts)
+      hits := 0 // findings written by this job; with --only-report-hits, jobs without any stay silent
+      defer func() {
+        duration := jobs.finish(jobID)
+        if (timings || verbose) && (!onlyReportHits || hits > 0) {
+          fmt.Printf("Finished %s in %s\n", jobKey, duration.Round(time.Millisecond))
         }
+      }()
 
-        // Skip if techs is empty
-        if len(techs) == 0 {
+      // Bracket the job with --pre-cmd/--post-cmd; a failing pre-cmd skips the job with --skip-on-pre-cmd-fail.
+      // Hooks count against --max-procs like the commands themselves and are left out once it is reached.
+      if preCmd != "" && procs.take() {
+        hookStr := hookCommand(preCmd, hostInput, tech)
+        if process {
+          fmt.Printf("Running pre-cmd: [%s]\n", hookStr)
+        }
+        if err := runHook(hookStr, workdir, childEnv); err != nil {
+          if skipOnPreCmdFail {
+            fmt.Printf("Skipping %s: pre-cmd failed: %s\n", jobKey, err)
+            return
+          }
           if verbose {
-            fmt.Printf("SKIPPED: %s - no matching technologies found\n", techData.Host)
+            fmt.Printf("Error running pre-cmd for %s: %s\n", jobKey, err)
           }
-          return
         }
+      }
+      if postCmd != "" {
+        defer func() {
+          if !procs.take() {
+            return
+          }
+          hookStr := hookCommand(postCmd, hostInput, tech)
+          if process {
+            fmt.Printf("Running post-cmd: [%s]\n", hookStr)
+          }
+          if err := runHook(hookStr, workdir, childEnv); err != nil && verbose {
+            fmt.Printf("Error running post-cmd for %s: %s\n", jobKey, err)
+          }
+        }()
+      }
 
-        tech := strings.ToLower(strings.Join(techs, ","))
+      // Run each --cmd template in turn; one failing doesn't stop the next, but keeps the job out of --resume
+      failed := false
+      matched := false
+      capped := false // stopped at --max-output-lines-per-job
+      lines := 0      // output lines of all templates, for --max-output-lines-per-job
+      headerWritten := false // --output-append-host-comment separator written for this job
+      // runTemplate runs the i-th --cmd template, in its own func so its timeout context and output reader are
+      // released before the next template starts; it returns false once --max-procs allows no more commands
+      runTemplate := func(i int, template string) bool {
+        // With several templates, terminal lines are labeled with the number of the --cmd they came from
+        cmdNumber := 0
+        if len(templates) > 1 {
+          cmdNumber = i + 1
+        }
 
         var cmdStr string
-        if strings.Contains(nucleiCmdStr, "-tc") {
+        if strings.Contains(template, "-tc") {
           // Modify to use the -tc format
-          var conditions []string
-          for _, t := range techs {
-            conditions = append(conditions, fmt.Sprintf("contains(to_lower(name),'%s')", strings.ToLower(t)))
-          }
-          cmdStr = strings.Replace(nucleiCmdStr, "{tech}", fmt.Sprintf("\"%s\"", strings.Join(conditions, " || ")), -1)
-        } else if strings.Contains(nucleiCmdStr, "-tags") {
-          // Use the -tags format as-is
-          cmdStr = strings.Replace(nucleiCmdStr, "{tech}", tech, -1)
+          cmdStr = strings.Replace(template, placeholderTech, tcExpression(techs), -1)
         } else {
-          // Default: replace {tech} as-is
-          cmdStr = strings.Replace(nucleiCmdStr, "{tech}", tech, -1)
+          // Use the -tags format, or by default {tech}, as-is unless --unsafe-tech-policy escape quotes it
+          techArg := tech
+          if unsafeTechPolicy == "escape" {
+            techArg = shellQuote(tech)
+          }
+          cmdStr = strings.Replace(template, placeholderTech, techArg, -1)
+        }
+
+        cmdStr = strings.Replace(cmdStr, placeholderTechTemplates, techTemplatesList(templatesDir, techs, caseSensitive), -1)
+        // The child environment names the job's host and techs, and its User-Agent for {ua}, which is inserted
+        // as one quoted sh word (empty without --random-ua or --ua-file) since User-Agents hold ; ( and )
+        jobEnv := jobTargetEnv(childEnv, hostInput, tech)
+        ua := randomUserAgent(userAgents)
+        cmdStr = strings.Replace(cmdStr, placeholderUA, shellQuote(ua), -1)
+        if ua != "" {
+          jobEnv = append(jobEnv, userAgentEnv+"="+ua)
         }
+        // Hosts come from the input, so {host} is inserted as one quoted word that can't run commands
+        cmdStr = strings.Replace(cmdStr, placeholderHost, shellQuote(hostInput), -1)
+        if jsonFields {
+          cmdStr = substituteFields(cmdStr, fields)
+        }
+        cmdStr = prefixCommand(commandPrefix, cmdStr)
 
         if process {
-          fmt.Printf("Running Nuclei: [echo \"%s\" | %s]\n", techData.Host, cmdStr)
+          if hostMode == "arg" {
+            fmt.Printf("Running Nuclei: [%s]\n", cmdStr)
+          } else {
+            fmt.Printf("Running Nuclei: [echo \"%s\" | %s]\n", label, cmdStr)
+          }
+        }
+
+        // Limit the run time with --timeout, or the --timeout-map entry of its techs; --first-match stops it early
+        ctx, stop := context.WithCancel(runCtx)
+        defer stop()
+        timeout := jobTimeout(techs, globalTimeout, timeoutMap, caseSensitive)
+        if timeout > 0 {
+          var cancel context.CancelFunc
+          ctx, cancel = context.WithTimeout(ctx, timeout)
+          defer cancel()
         }
 
-        // Run the nuclei command
-        cmd := exec.Command("sh", "-c", cmdStr)
-        cmd.Stdin = strings.NewReader(techData.Host)
+        // Run the nuclei command, piping the host on stdin unless --host-mode is arg
+        if !procs.take() {
+          return false
+        }
+        cmd := exec.CommandContext(ctx, "sh", "-c", limits.wrap(cmdStr))
+        cmd.Dir = workdir
+        // Every stop (timeout, --first-match, --max-runtime, an abort, the line cap or Ctrl-C) cancels ctx, which
+        // kills the whole process group so the programs sh started don't keep the pipes open
+        killProcessGroupOnCancel(cmd)
+        limits.prepare(cmd)
+        if hostMode != "arg" {
+          cmd.Stdin = strings.NewReader(hostInput)
+        }
+        cmd.Env = append(os.Environ(), jobEnv...)
         stdoutPipe, _ := cmd.StdoutPipe()
         stderrPipe, _ := cmd.StderrPipe()
 
@@ -219,47 +856,583 @@ Examples:
           if verbose {
             fmt.Printf("Error starting nuclei command: %s\n", err)
           }
-          return
+          breaker.record(true)
+          abort.fail(jobKey, err)
+          failed = true
+          return true
+        }
+        if err := limits.started(cmd); err != nil && verbose {
+          fmt.Printf("Error applying --nice to %s: %s\n", jobKey, err)
+        }
+
+        // With --stdout-format jsonl, each line is printed as a JSON object naming its host and tech
+        printLine := func(line string) {
+          if stdoutFormat == "jsonl" {
+            line = formatResultLine(resultHost(line, hosts, label), mapTechNames(tech, techAliases), line, cmdNumber)
+          } else if cmdNumber > 0 {
+            line = fmt.Sprintf("[cmd %d] %s", cmdNumber, line)
+          }
+          emit(line)
         }
 
         // Handle the output
-        scanner := bufio.NewScanner(io.MultiReader(stdoutPipe, stderrPipe))
+        var held []string // --only-report-hits output waiting for the job's first finding
+        // Read stdout and stderr together so their lines are printed in the order the job wrote them
+        scanner := mergeLines(stdoutPipe, stderrPipe)
+        defer scanner.stop()
         for scanner.Scan() {
           line := scanner.Text()
-          fmt.Println(line)
+
+          // Kill a runaway job once it passes --max-output-lines-per-job, so it can't fill the disk
+          if maxJobLines > 0 && lines >= maxJobLines {
+            fmt.Printf("Warning: %s printed more than %d lines, stopping it (--max-output-lines-per-job)\n", jobKey, maxJobLines)
+            capped = true
+            stop()
+            break
+          }
+          lines++
 
           // Check if the line starts with three sets of square brackets
           parts := strings.Fields(line)
-          if len(parts) >= 3 && strings.HasPrefix(parts[0], "[") && strings.HasPrefix(parts[1], "[") && strings.HasPrefix(parts[2], "[") {
+          isFinding := len(parts) >= 3 && strings.HasPrefix(parts[0], "[") && strings.HasPrefix(parts[1], "[") && strings.HasPrefix(parts[2], "[")
+
+          // Drop findings below --min-severity from both the terminal and the output files; the same severity
+          // picks the --split-output-by-severity file
+          severity := "unknown"
+          if isFinding {
+            severity = parseSeverity(line)
+          }
+          if isFinding && severityRank(severity) < minSeverityRank {
+            continue
+          }
+
+          // Findings (first occurrence only with --dedup-output) go to the output files, and with
+          // --output-stdout-only-findings they are also all that reaches the terminal
+          written := isFinding && deduper.first(line)
+          if !quietOutput && (written || !stdoutOnlyFindings) {
+            if onlyReportHits && hits == 0 && !written {
+              held = append(held, line)
+            } else {
+              for _, heldLine := range held {
+                printLine(heldLine)
+              }
+              held = nil
+              printLine(line)
+            }
+          }
+
+          if written {
+            hits++
+            tally.finding(resultHost(line, hosts, label))
+            findingHooks.fire(resultHost(line, hosts, label), tech, line)
             if Output != "" {
               // Append the filtered output line to the specified file
-              if _, err := outputFile.WriteString(line + "\n"); err != nil && verbose {
-                fmt.Printf("Error writing to output file: %s\n", err)
+              entry := line + "\n"
+              lineHost := resultHost(line, hosts, label)
+              if jsonPretty {
+                entry = formatPrettyResult(lineHost, mapTechNames(tech, techAliases), line)
+              } else if parseFindings != "" {
+                entry = formatFinding(parseFinding(line, lineHost, mapTechNames(tech, techAliases)), parseFindings)
+              }
+              if hostComment && !headerWritten {
+                // Start the job's block with a separator, in the same write so it stays attached to the first line
+                entry = fmt.Sprintf("# ==== %s (%s) ====\n", label, mapTechNames(tech, techAliases)) + entry
+                headerWritten = true
+              }
+              if _, err := outputFile.WriteString(entry); errors.Is(err, errInvalidOutput) {
+                // --validate-output caught a corrupt write; keep the job out of --resume so it runs again
+                fmt.Printf("Error: %s (%s)\n", err, jobKey)
+                failed = true
+              } else if err != nil {
+                if verbose {
+                  fmt.Printf("Error writing to output file: %s\n", err)
+                }
+              } else if resultIndex != nil {
+                resultIndex.add(lineHost)
               }
             }
+            if severityOutput != nil {
+              if err := severityOutput.write(severity, line); err != nil && verbose {
+                fmt.Printf("Error writing to severity output file: %s\n", err)
+              }
+            }
+            if firstMatch {
+              if verbose {
+                fmt.Printf("First finding for %s, stopping its scan\n", label)
+              }
+              matched = true
+              stop()
+              break
+            }
           }
         }
 
-        if err := cmd.Wait(); err != nil && verbose {
-          fmt.Printf("Error waiting for nuclei command: %s\n", err)
+        if err := cmd.Wait(); err != nil && !matched && !capped {
+          if runCtx.Err() == nil {
+            breaker.record(true)
+            abort.fail(jobKey, err)
+          }
+          if runCtx.Err() == context.DeadlineExceeded {
+            fmt.Printf("Stopped by --max-runtime: %s\n", jobKey)
+          } else if ctx.Err() == context.DeadlineExceeded && (!onlyReportHits || hits > 0) {
+            fmt.Printf("Timed out after %s: %s\n", timeout, jobKey)
+          } else if verbose {
+            fmt.Printf("Error waiting for nuclei command: %s\n", err)
+          }
+          failed = true
+          return true
+        }
+        // A job cut off at --max-output-lines-per-job didn't finish, so keep it out of --resume and --seen-db
+        if capped {
+          breaker.record(true)
+          failed = true
+          return true
+        }
+        breaker.record(false)
+        return true
+      }
+      for i, template := range templates {
+        if matched || capped {
+          break
         }
+        if !runTemplate(i, template) {
+          return
+        }
+      }
+      if failed {
+        return
+      }
 
-      }(techData)
+      for _, host := range hosts {
+        if err := resume.record(host, tech); err != nil && verbose {
+          fmt.Printf("Error writing to resume file: %s\n", err)
+        }
+        for _, t := range techs {
+          if err := seen.record(resumeKey(host, techCase(t, caseSensitive))); err != nil && verbose {
+            fmt.Printf("Error writing to --seen-db: %s\n", err)
+          }
+        }
+      }
+    }
+
+    // With --batch-size hosts sharing the same techs are scanned together, batchSize at a time
+    batcher := newHostBatcher(batchSize, caseSensitive)
+
+    // With --group-by-tech hosts are buffered per tech and each tech runs once over all of its hosts
+    groups := make(map[string][]string)
+    var groupOrder []string
+    grouped := make(map[string]bool)
+
+    // With --normalize-host, host/tech jobs already dispatched for an equivalent host are skipped
+    seenJobs := make(map[string]bool)
+
+    // With -tc, tech sets whose expression is longer than --tc-max-length are split over several jobs
+    techChunks := func(techs []string) [][]string {
+      if !strings.Contains(nucleiCmdStr, "-tc") {
+        return [][]string{techs}
+      }
+      return tcChunks(techs, tcMaxLength)
     }
 
-    wg.Wait() // Wait for all goroutines to finish
+    dispatched := 0 // hosts launched so far, for --limit
+    var cidrRecords []TechData // addresses of an --expand-cidr range still to scan
+    var nullTechHosts []string // hosts to refingerprint once the input is read, with --refingerprint-null
+    refingerprinted := false
+    for {
+      // Pause while more jobs fail than --error-threshold allows
+      breaker.wait(runCtx)
+
+      // Stop launching jobs once --max-runtime or --max-procs is reached
+      if runCtx.Err() != nil || procs.exhausted() {
+        break
+      }
+
+      // Stop reading input once --limit hosts have been dispatched; running jobs still finish below
+      if limit > 0 && dispatched >= limit {
+        if verbose {
+          fmt.Printf("Reached --limit of %d hosts, not reading further input\n", limit)
+        }
+        break
+      }
+
+      var techData TechData
+      if len(cidrRecords) > 0 {
+        techData, cidrRecords = cidrRecords[0], cidrRecords[1:]
+      } else if err := decoder.Decode(&techData); err == io.EOF {
+        // With --refingerprint-null, give the hosts that had no techs a second techfinder run and scan its records
+        if len(nullTechHosts) == 0 {
+          break
+        }
+        if verbose {
+          fmt.Printf("Re-running techfinder on %d hosts with tech field as null\n", len(nullTechHosts))
+        }
+        decoder, err = refingerprint(nullTechHosts, continueOnTechfinderError)
+        nullTechHosts, refingerprinted = nil, true
+        if err != nil {
+          fmt.Printf("Error re-running techfinder for --refingerprint-null: %s\n", err)
+          break
+        }
+        continue
+      } else if err != nil {
+        fmt.Printf("Error decoding JSON: %s\n", err)
+        os.Exit(1)
+      }
+
+      // With --expand-cidr, a CIDR host is scanned as one record per address, with the same techs
+      if expandCIDR {
+        addrs, isCIDR, err := expandCIDRHost(techData.Host, expandCIDRMax)
+        if err != nil {
+          fmt.Printf("Skipping %s: %s\n", techData.Host, err)
+          continue
+        }
+        if isCIDR {
+          for _, addr := range addrs {
+            record := techData
+            record.Host = addr
+            cidrRecords = append(cidrRecords, record)
+          }
+          continue
+        }
+      }
+
+      techData.Host = rewriteHost(techData.Host, hostRewrites)
+      if normalizeHosts {
+        techData.Host = normalizeHost(techData.Host)
+      }
+
+      // Skip processing if tech is nil
+      if techData.Tech == nil {
+        if refingerprintNull && !refingerprinted {
+          nullTechHosts = append(nullTechHosts, techData.Host)
+          continue
+        }
+        if verbose {
+          fmt.Printf("Skipping URL with tech field as null: %s\n", techData.Host)
+        }
+        continue
+      }
+
+      // With --require-all-tech, only hosts running every listed tech are scanned
+      if missing := missingRequiredTechs(techData.Tech, requiredTechs, techSegment, caseSensitive); len(missing) > 0 {
+        if verbose {
+          fmt.Printf("SKIPPED: %s - missing required techs: %s\n", techData.Host, strings.Join(missing, ", "))
+        }
+        continue
+      }
+
+      // Process tech field with include/exclude logic
+      var techs []string
+      for _, t := range techData.Tech {
+        tech, version := splitTech(t, techSegment)
+        // With --tech-version-filter, only keep constrained techs whose version satisfies the constraints
+        if len(versionConstraints) > 0 {
+          if !techVersionAllowed(versionConstraints, tech, version) {
+            if verbose {
+              fmt.Printf("Skipping tech %s for host %s (version %q not allowed by --tech-version-filter)\n", tech, techData.Host, version)
+            }
+            continue
+          }
+        }
+        // With --unsafe-tech-policy drop, skip techs containing any of --unsafe-tech-chars
+        if unsafeTechPolicy == "drop" && hasUnsafeChars(tech, unsafeTechChars) {
+          if verbose {
+            fmt.Printf("Skipping tech %q for host %s (contains --unsafe-tech-chars)\n", tech, techData.Host)
+          }
+          continue
+        }
+        // Ignore technologies with spaces
+        if !strings.Contains(tech, " ") {
+          name := techCase(tech, caseSensitive)
+          
+          // Keep the techs in the include list (all of them without one), then drop the ones in the exclude list
+          included := len(includeList) == 0 || matchesTechList(includeList, name, techPrefixMatch)
+          if included && !matchesTechList(excludeList, name, techPrefixMatch) {
+            techs = append(techs, tech)
+          }
+        }
+      }
+
+      // With {tech-templates}, only keep techs that have their own template directory
+      if strings.Contains(nucleiCmdStr, placeholderTechTemplates) {
+        var withTemplates []string
+        for _, t := range techs {
+          if dir, ok := techTemplatesDir(templatesDir, t, caseSensitive); ok {
+            withTemplates = append(withTemplates, t)
+          } else if verbose {
+            fmt.Printf("Skipping tech %s for host %s (no template directory %s)\n", t, techData.Host, dir)
+          }
+        }
+        techs = withTemplates
+      }
+
+      // Skip if techs is empty
+      if len(techs) == 0 {
+        if verbose {
+          fmt.Printf("SKIPPED: %s - no matching technologies found\n", techData.Host)
+        }
+        continue
+      }
+
+      // With --sort-techs, the same tech set gives the same {tech} string whatever the input order
+      if sortTechs {
+        sort.Slice(techs, func(i, j int) bool {
+          return strings.ToLower(techs[i]) < strings.ToLower(techs[j])
+        })
+      }
+
+      if normalizeHosts {
+        var unseen []string
+        for _, t := range techs {
+          key := resumeKey(techData.Host, techCase(t, caseSensitive))
+          if seenJobs[key] {
+            if verbose {
+              fmt.Printf("Skipping tech %s for host %s (duplicate of an already dispatched host)\n", t, techData.Host)
+            }
+            continue
+          }
+          seenJobs[key] = true
+          unseen = append(unseen, t)
+        }
+        if len(unseen) == 0 {
+          continue
+        }
+        techs = unseen
+      }
+
+      // Drop techs scanned on this host less than --skip-seen-within ago
+      if seen != nil {
+        var stale []string
+        for _, t := range techs {
+          if seen.recent(resumeKey(techData.Host, techCase(t, caseSensitive))) {
+            if verbose {
+              fmt.Printf("Skipping tech %s for host %s (scanned within --skip-seen-within)\n", t, techData.Host)
+            }
+            continue
+          }
+          stale = append(stale, t)
+        }
+        if len(stale) == 0 {
+          continue
+        }
+        techs = stale
+      }
+
+      // Keep only the first --sample-per-tech hosts of each tech
+      if sampler != nil {
+        var sampled []string
+        for _, t := range techs {
+          if sampler.keep(techCase(t, caseSensitive)) {
+            sampled = append(sampled, t)
+          } else if verbose {
+            fmt.Printf("Skipping tech %s for host %s (--sample-per-tech reached)\n", t, techData.Host)
+          }
+        }
+        if len(sampled) == 0 {
+          continue
+        }
+        techs = sampled
+      }
+
+      // With --print-plan, show what would be scanned instead of scanning it
+      if printPlan {
+        var planned []string
+        for _, t := range techs {
+          planned = append(planned, techCase(t, caseSensitive))
+        }
+        printPlanEntry(techData.Host, planned)
+        dispatched++
+        continue
+      }
+
+      if groupByTech {
+        queued := false
+        for _, t := range techs {
+          tech := techCase(t, caseSensitive)
+          if grouped[tech+"|"+techData.Host] {
+            continue
+          }
+          if resume.has(techData.Host, tech) {
+            if verbose {
+              fmt.Printf("Skipping tech %s for host %s (already completed in resume file)\n", tech, techData.Host)
+            }
+            continue
+          }
+          queued = true
+          resume.expect(techData.Host, 1)
+          grouped[tech+"|"+techData.Host] = true
+          if _, ok := groups[tech]; !ok {
+            groupOrder = append(groupOrder, tech)
+          }
+          groups[tech] = append(groups[tech], techData.Host)
+        }
+        if queued {
+          dispatched++
+        }
+        continue
+      }
+
+      // Each chunk of techs is its own job in the resume file, so only the unfinished chunks run again
+      var pending [][]string
+      for _, chunk := range techChunks(techs) {
+        if !resume.has(techData.Host, techCase(strings.Join(chunk, ","), caseSensitive)) {
+          pending = append(pending, chunk)
+        }
+      }
+      if len(pending) == 0 {
+        if verbose {
+          fmt.Printf("Skipping host %s (already completed in resume file)\n", techData.Host)
+        }
+        continue
+      }
+
+      dispatched++
+
+      if batcher != nil {
+        resume.expect(techData.Host, len(techChunks(techs)))
+        if batch, full := batcher.add(techData.Host, techs); full {
+          for _, chunk := range techChunks(batch.techs) {
+            wg.Add(1)
+            sem.Acquire(context.Background(), jobWeight(chunk, techWeights, parallel, caseSensitive)) // Acquire a semaphore
+            go runJob(batch.hosts, chunk, nil, ordered.begin())
+          }
+        }
+        continue
+      }
+
+      if verbose && len(pending) > 1 {
+        fmt.Printf("Splitting %d techs of %s over %d jobs (--tc-max-length %d)\n", len(techs), techData.Host, len(pending), tcMaxLength)
+      }
+      resume.expect(techData.Host, len(pending))
+      // Decode the record's other fields only when --json-fields substitutes them
+      var fields map[string]interface{}
+      if jsonFields {
+        fields = techData.extraFields()
+      }
+      for _, chunk := range pending {
+        wg.Add(1)
+        sem.Acquire(context.Background(), jobWeight(chunk, techWeights, parallel, caseSensitive)) // Acquire a semaphore
+        go runJob([]string{techData.Host}, chunk, fields, ordered.begin())
+      }
+    }
+
+    if printPlan {
+      return
+    }
+
+    // Scan the partially filled --batch-size batches left at the end of the input
+    for _, batch := range batcher.flush() {
+      breaker.wait(runCtx) // pause while more jobs fail than --error-threshold allows
+      if runCtx.Err() != nil || procs.exhausted() {
+        break
+      }
+      for _, chunk := range techChunks(batch.techs) {
+        wg.Add(1)
+        sem.Acquire(context.Background(), jobWeight(chunk, techWeights, parallel, caseSensitive)) // Acquire a semaphore
+        go runJob(batch.hosts, chunk, nil, ordered.begin())
+      }
+    }
+
+    for _, tech := range groupOrder {
+      breaker.wait(runCtx) // pause while more jobs fail than --error-threshold allows
+      if runCtx.Err() != nil || procs.exhausted() {
+        break
+      }
+      if verbose {
+        fmt.Printf("Running tech %s over %d hosts\n", tech, len(groups[tech]))
+      }
+      wg.Add(1)
+      sem.Acquire(context.Background(), jobWeight([]string{tech}, techWeights, parallel, caseSensitive)) // Acquire a semaphore
+      go runJob(groups[tech], []string{tech}, nil, ordered.begin())
+    }
+
+    // Wait for all goroutines to finish, or only --shutdown-grace once interrupted, printing what they left held
+    // and dropping any later lines of theirs
+    if !interrupt.wait(&wg) {
+      ordered.drain()
+      stdout.endJobs()
+    }
+    close(stopWatch)
+    findingHooks.close()
+    if !stdoutOnlyFindings && !silent {
+      stdout.println(tally.summary())
+    }
+
+    if resultIndex != nil {
+      if err := resultIndex.write(indexPath(Output)); err != nil {
+        fmt.Printf("Error writing index file: %s\n", err)
+      }
+    }
+
+    // Point latest<ext> at this run's output with --output-symlink-latest
+    if symlinkLatest {
+      if err := linkLatest(Output, outputFile.Path()); err != nil {
+        fmt.Printf("Error linking latest output: %s\n", err)
+      }
+    }
+
+    // With --summary-json the run stats are printed as the last stdout line, whatever the exit code
+    printSummaryJSON := func(stopped string, exitCode int) {
+      if !summaryJSON {
+        return
+      }
+      summary := runSummary{Command: "nuclei", Seconds: jobs.elapsed().Round(time.Millisecond).Seconds(), Stopped: stopped, ExitCode: exitCode}
+      tally.fill(&summary)
+      stdout.println(summary.String())
+    }
+
+    // Deferred closes don't run on os.Exit, so close the outputs (giving a .zst file its final frame) before
+    // exiting with the --max-runtime, --abort-on-first-error, interrupt or --fail-on-findings code
+    closeOutputs := func() {
+      if outputFile != nil {
+        outputFile.Close()
+      }
+      if severityOutput != nil {
+        severityOutput.close()
+      }
+      resume.close()
+      seen.close()
+    }
+
+    if runCtx.Err() == context.DeadlineExceeded || abort.failed() || interrupt.interrupted() {
+      closeOutputs()
+      if interrupt.interrupted() {
+        if resumeFile != "" {
+          fmt.Printf("Completed jobs saved to %s; rerun with the same --resume to continue\n", resumeFile)
+        }
+        printSummaryJSON("interrupted", exitInterrupted)
+        os.Exit(exitInterrupted)
+      }
+      if abort.failed() {
+        printSummaryJSON("job-error", exitJobError)
+        os.Exit(exitJobError)
+      }
+      fmt.Printf("Reached --max-runtime of %s, stopped with partial results\n", maxRuntime)
+      printSummaryJSON("max-runtime", exitMaxRuntime)
+      os.Exit(exitMaxRuntime)
+    }
+
+    if timings || verbose {
+      jobs.printSlowest()
+    }
+
+    if failOnFindings && tally.findings.Load() > 0 {
+      printSummaryJSON("", exitFindings)
+      closeOutputs()
+      os.Exit(exitFindings)
+    }
+    printSummaryJSON("", 0)
   },
 }
 
 // Helper function to parse tech input (supports both comma-separated values and file paths)
-func parseTechInput(input string) ([]string, error) {
+func parseTechInput(input string, caseSensitive bool) ([]string, error) {
   if input == "" {
     return []string{}, nil
   }
 
   // Check if input is a file that exists
   if _, err := os.Stat(input); err == nil {
-    // It's a file, read lines from the file
+    // It's a file, read lines from the file, skipping blank lines and # comments
     file, err := os.Open(input)
     if err != nil {
       return nil, err
@@ -270,8 +1443,8 @@ func parseTechInput(input string) ([]string, error) {
     scanner := bufio.NewScanner(file)
     for scanner.Scan() {
       tech := strings.TrimSpace(scanner.Text())
-      if tech != "" {
-        techs = append(techs, strings.ToLower(tech))
+      if tech != "" && !strings.HasPrefix(tech, "#") {
+        techs = append(techs, techCase(tech, caseSensitive))
       }
     }
     return techs, scanner.Err()
@@ -280,7 +1453,7 @@ func parseTechInput(input string) ([]string, error) {
   // Otherwise, treat as comma-separated list
   techs := strings.Split(input, ",")
   for i := range techs {
-    techs[i] = strings.TrimSpace(strings.ToLower(techs[i]))
+    techs[i] = strings.TrimSpace(techCase(techs[i], caseSensitive))
   }
   return techs, nil
 }
@@ -298,11 +1471,108 @@ func contains(slice []string, item string) bool {
 func init() {
   rootCmd.AddCommand(nucleiCmd)
 
-  nucleiCmd.Flags().StringP("cmd", "c", "", "The nuclei command template")
+  nucleiCmd.Flags().StringArrayP("cmd", "c", nil, "The nuclei command template; repeat it to run several commands in turn for each job")
+  nucleiCmd.Flags().StringArray("var", nil, "Per-run placeholder name=value substituted for {name} in the command template, repeatable (e.g. --var tpl=~/mytemplates)")
+  nucleiCmd.Flags().String("cmd-file", "", "File containing the nuclei command template, as an alternative to --cmd")
+  nucleiCmd.Flags().Bool("summary-json", false, "Print the run stats (jobs, findings, hosts, duration, exit code) as a JSON object on the last stdout line")
+  nucleiCmd.Flags().Bool("fail-on-findings", false, "Exit with code 2 if any finding was reported, for gating CI pipelines")
+  nucleiCmd.Flags().String("on-finding-exec", "", "Command run in the background for each finding, with {host}, {tech} and {finding} substituted (e.g. to open a ticket)")
+  nucleiCmd.Flags().Int("on-finding-rate", 5, "Maximum --on-finding-exec commands started per second")
+  nucleiCmd.Flags().Bool("output-stdout-only-findings", false, "Only print the finding lines written to --output to the terminal, hiding progress and other output")
+  nucleiCmd.Flags().String("stdout-format", "raw", "Format of the output printed to the terminal: raw (command output lines) or jsonl (one {\"host\",\"tech\",\"output\"} object per line)")
+  nucleiCmd.Flags().Bool("silent", false, "Don't print the banner and the final summary, so stdout only carries the scan output")
+  nucleiCmd.Flags().Bool("quiet-output", false, "Don't print command output to the terminal, only write it to --output")
   nucleiCmd.Flags().Bool("verbose", false, "Enable verbose output for debugging purposes.")
   nucleiCmd.Flags().Bool("process", false, "Show which URL is running on Nuclei.")
   nucleiCmd.Flags().Int("parallel", 50, "Number of parallel processes")
-  nucleiCmd.Flags().StringP("output", "o", "", "File to save output")
-  nucleiCmd.Flags().StringP("exclude-tech", "e", "", "Comma-separated list of technologies to exclude, or path to a file with technologies (one per line)")
+  nucleiCmd.Flags().Int("max-procs", 0, "Hard cap on the total number of commands started in the run, independent of --parallel (0 for no cap)")
+  nucleiCmd.Flags().String("tech-weight", "", "Parallel slots taken by a job per tech, e.g. \"confluence=4,default=1\", so heavy techs run at lower concurrency")
+  nucleiCmd.Flags().String("templates-dir", "/root/tech-templates", "Base directory with one template folder per tech, used for the {tech-templates} placeholder")
+  nucleiCmd.Flags().Bool("first-match", false, "Stop scanning a host as soon as it produces its first finding")
+  nucleiCmd.Flags().Bool("group-by-tech", false, "Buffer the input and run one nuclei process per tech over all hosts running it")
+  nucleiCmd.Flags().Bool("only-live", false, "Probe hosts with --live-probe first and only scan those that respond")
+  nucleiCmd.Flags().String("live-probe", "httpx -silent", "Command used by --only-live; hosts are fed on stdin and a host is live if an output line starts with it")
+  nucleiCmd.Flags().Duration("live-probe-timeout", 2*time.Minute, "Kill a --live-probe run that takes longer than this, keeping the hosts it reported so far (0 for no limit)")
+  nucleiCmd.Flags().String("parse-findings", "", "Write findings to --output as structured records (template-id, severity, host, matched-url, ...): json (one object per line) or csv")
+  nucleiCmd.Flags().String("seen-db", "", "File remembering when each host|tech pair was last scanned, kept across runs (see --skip-seen-within)")
+  nucleiCmd.Flags().Duration("skip-seen-within", 0, "Skip host/tech pairs the --seen-db saw scanned less than this long ago, e.g. 24h (0 only records)")
+  nucleiCmd.Flags().String("resume", "", "File recording completed host|tech jobs; jobs already in it are skipped so an interrupted run can continue")
+  nucleiCmd.Flags().String("resume-granularity", "tech", "What --resume records: tech (each host|tech job) or host (a host once all its jobs completed)")
+  nucleiCmd.Flags().Bool("sequential", false, "Run one job at a time in input order so the output is identical across runs (overrides --parallel)")
+  nucleiCmd.Flags().Bool("concurrency-auto", false, "Size the number of parallel processes from the CPU count (an explicit --parallel wins)")
+  nucleiCmd.Flags().Int("sample-per-tech", 0, "Only scan the first N hosts of each technology for a quick coverage check (0 to scan all)")
+  nucleiCmd.Flags().String("json-host-key", "host", "JSON key holding the host in the input records, e.g. url")
+  nucleiCmd.Flags().String("json-tech-key", "tech", "JSON key holding the techs in the input records, e.g. technologies (a list of names or of {name, version} objects, an object keyed by name or a comma-separated string)")
+  nucleiCmd.Flags().Bool("case-sensitive", false, "Match and substitute tech names exactly as the input has them instead of lowercasing them")
+  nucleiCmd.Flags().Bool("rare-first", false, "Scan the techs found on the fewest hosts first, so an early stop still covers the uncommon ones (buffers the whole input)")
+  nucleiCmd.Flags().Bool("sample-random", false, "Pick the --sample-per-tech hosts at random instead of the first ones (buffers the whole input)")
+  nucleiCmd.Flags().Bool("print-plan", false, "Print the {\"host\", \"tech\"} record of techs each host would be scanned for after filtering, then exit without scanning")
+  nucleiCmd.Flags().Bool("ordered-output", false, "Run jobs with --parallel but print their output in input order, holding the output of jobs that finish early")
+  nucleiCmd.Flags().String("ordered-buffer", "64MB", "Memory for output held by --ordered-output before it spills to a temp file, e.g. 16MB (0 for no limit)")
+  nucleiCmd.Flags().Bool("expand-cidr", false, "Scan a CIDR host such as 192.168.0.0/24 as one job per address, each with the record's techs")
+  nucleiCmd.Flags().Int("expand-cidr-max", 65536, "Largest CIDR range --expand-cidr expands; bigger ranges are skipped")
+  nucleiCmd.Flags().Duration("shutdown-grace", 10*time.Second, "On Ctrl-C, how long running jobs get to stop and have their output written before the outputs are closed")
+  nucleiCmd.Flags().Bool("abort-on-first-error", false, "Stop the whole scan and exit with code 4 as soon as a command fails, e.g. while testing a template")
+  nucleiCmd.Flags().Bool("validate-only", false, "Check the flags, filter and map files and the command template, then exit without reading input or scanning")
+  nucleiCmd.Flags().Int("limit", 0, "Only scan the first N hosts that have technologies left after filtering (0 for no limit)")
+  nucleiCmd.Flags().StringP("output", "o", "", "File to save output; {timestamp} is replaced with the run's start time")
+  nucleiCmd.Flags().Bool("output-daily", false, "Write --output under YYYY/MM/DD/ directories next to the given path, switching directory at midnight")
+  nucleiCmd.Flags().Bool("output-symlink-latest", false, "At the end of the run, point a latest<ext> symlink next to the output at this run's file (needs {timestamp} in --output)")
+  nucleiCmd.Flags().Bool("random-ua", false, "Pick a random User-Agent per job for the {ua} placeholder and the VULNTECHFINDER_UA environment variable")
+  nucleiCmd.Flags().String("ua-file", "", "File with one User-Agent per line used instead of the built-in list (implies --random-ua)")
+  nucleiCmd.Flags().String("workdir", "", "Directory the commands run in, so relative wordlist/template paths resolve against it (default: current directory)")
+  nucleiCmd.Flags().String("command-prefix", "", "Wrapper prepended to every resolved command, e.g. \"proxychains -q\" or \"nice -n 10\"")
+  nucleiCmd.Flags().String("pre-cmd", "", "Command run before each job, with {host} and {tech} substituted (e.g. DNS warm-up or logging)")
+  nucleiCmd.Flags().String("post-cmd", "", "Command run after each job, with {host} and {tech} substituted")
+  nucleiCmd.Flags().Bool("skip-on-pre-cmd-fail", false, "Skip a job when its --pre-cmd exits with an error")
+  nucleiCmd.Flags().Bool("output-append-host-comment", false, "Write a \"# ==== host (tech) ====\" separator before each job's lines in --output (use with --sequential to keep blocks contiguous)")
+  nucleiCmd.Flags().Bool("validate-output", false, "Read every write to the JSON --output back and check it parses, failing the job on corrupt output")
+  nucleiCmd.Flags().Bool("output-json-pretty", false, "Write each output line to --output as an indented JSON object {host, tech, output} and a host -> count index to <output>-index.json")
+  nucleiCmd.Flags().String("tech-map-output", "", "Tech aliases \"raw=canonical,...\" (or a file with one per line) used for tech names in output files; filters and {tech} still use the raw names")
+  nucleiCmd.Flags().Bool("output-include-command", false, "Start the run's part of --output with a line recording the vulntechfinder version, start time and effective command")
+  nucleiCmd.Flags().String("output-max-size", "", "Rotate --output to name.1, name.2, ... once it would grow past this size, e.g. 100MB (empty for no limit)")
+  nucleiCmd.Flags().Int("tc-max-length", 16384, "Split a host's techs over several jobs when their -tc expression is longer than this many bytes (0 for no limit)")
+  nucleiCmd.Flags().Bool("sort-techs", false, "Sort each host's filtered techs before building {tech}, so identical tech sets always give identical commands")
+  nucleiCmd.Flags().Int("batch-size", 0, "Feed up to N hosts with the same filtered techs to one process on stdin instead of one process per host (no {field} placeholders)")
+  nucleiCmd.Flags().Float64("error-threshold", 0, "Pause launching jobs for --error-backoff when more than this fraction of the last 20 jobs failed, e.g. 0.5 (0 to disable)")
+  nucleiCmd.Flags().Duration("error-backoff", 30*time.Second, "How long --error-threshold pauses new jobs before probing again")
+  nucleiCmd.Flags().String("unsafe-tech-policy", "keep", "What to do with techs containing --unsafe-tech-chars: keep, drop or escape (insert {tech} as one quoted sh word)")
+  nucleiCmd.Flags().String("unsafe-tech-chars", defaultUnsafeTechChars, "Characters that make a tech name unsafe for --unsafe-tech-policy")
+  nucleiCmd.Flags().String("tech-segment", "first", "Name used for vendor:product:version techs: first (the vendor), product or vendor-product, e.g. apache:tomcat:9 gives apache, tomcat or apache-tomcat")
+  nucleiCmd.Flags().Bool("only-report-hits", false, "Print nothing for jobs without findings: their nuclei output and timing/timeout messages are dropped")
+  nucleiCmd.Flags().Bool("refingerprint-null", false, "Run techfinder again over the input hosts whose tech field is null and scan them with the techs it finds")
+  nucleiCmd.Flags().Bool("continue-on-techfinder-error", false, "When techfinder exits with an error after printing some JSON, scan the hosts it did fingerprint instead of aborting")
+  nucleiCmd.Flags().Bool("strict-json", false, "Exit on input records with unknown fields, wrong types or no host instead of ignoring the anomaly")
+  nucleiCmd.Flags().Bool("fast-decode", false, "Decode NDJSON input (one record per line) on a pool of goroutines, for huge inputs with fast commands")
+  nucleiCmd.Flags().String("input", "", "Read input from this file, named pipe or Unix socket instead of stdin")
+  nucleiCmd.Flags().String("input-format", "auto", "Input format: auto (JSON, or a host list run through techfinder), csv or line (\"host tech1,tech2\" per line)")
+  nucleiCmd.Flags().Int("csv-host-column", 0, "Zero-based CSV column holding the host (--input-format csv)")
+  nucleiCmd.Flags().Int("csv-tech-column", 1, "Zero-based CSV column holding the tech list (--input-format csv)")
+  nucleiCmd.Flags().String("csv-tech-separator", ";", "Separator between techs in the CSV tech column (--input-format csv)")
+  nucleiCmd.Flags().Bool("tech-prefix-match", false, "Match --include-tech/--exclude-tech entries as prefixes, e.g. wordpress also matches wordpress-plugin-x")
+  nucleiCmd.Flags().String("require-all-tech", "", "Only scan hosts running all of these technologies, comma-separated or a file with one per line (e.g. \"php,wordpress\")")
+  nucleiCmd.Flags().StringArray("tech-version-filter", nil, "Only scan techs whose detected version satisfies a constraint, e.g. \"jira<9.4.0\" or \"confluence>=7.0,confluence<7.19\" (repeatable)")
+  nucleiCmd.Flags().Bool("exclude-noise", false, "Also exclude a built-in list of low-signal techs (CDNs, analytics, common JS libraries such as jquery, google-analytics, cloudflare)")
+  nucleiCmd.Flags().StringP("exclude-tech", "e", "", "Comma-separated list of technologies to exclude (applied after --include-tech), or path to a file with technologies (one per line)")
   nucleiCmd.Flags().StringP("include-tech", "i", "", "Comma-separated list of technologies to include (only these will be processed), or path to a file with technologies (one per line)")
+  nucleiCmd.Flags().String("extra-args", "", "Extra arguments appended to the command template before execution (e.g. \"-duc -silent -rl 50\")")
+  nucleiCmd.Flags().Bool("dedup-output", false, "Write each finding only once, ignoring timestamps and colors when comparing")
+  nucleiCmd.Flags().String("min-severity", "", "Drop findings below this severity from the terminal and output files (info, low, medium, high, critical)")
+  nucleiCmd.Flags().Bool("split-output-by-severity", false, "Also write findings to one file per severity, e.g. nuclei-output-critical.txt (output-<severity>.txt without --output)")
+  nucleiCmd.Flags().Bool("insecure", false, "Append the command's known flag for skipping TLS certificate verification")
+  nucleiCmd.Flags().String("insecure-flags", "", "Override or add skip-verify flags per tool for --insecure, e.g. \"nuclei=-some-flag,curl=-k\"")
+  nucleiCmd.Flags().Bool("normalize-host", false, "Canonicalize hosts (lowercase, no path, trailing dot or default port) and scan each host/tech only once")
+  nucleiCmd.Flags().StringArray("host-rewrite", nil, "Regex rule pattern=>replacement applied to each host before scanning, repeatable (e.g. \"^=>www.\" or \":\\d+$=>\")")
+  nucleiCmd.Flags().Bool("json-fields", false, "Replace {field} placeholders with extra fields of the input JSON, e.g. {status} or {title} (not with --group-by-tech)")
+  nucleiCmd.Flags().String("env-file", "", "File with KEY=VALUE lines added to the environment of each command (e.g. API tokens)")
+  nucleiCmd.Flags().String("host-mode", "stdin", "How the host is passed to the command: stdin, arg (replaces {host}) or both")
+  nucleiCmd.Flags().Bool("timings", false, "Print how long each job took and the slowest jobs at the end (also shown with --verbose)")
+  nucleiCmd.Flags().Duration("max-runtime", 0, "Stop the whole scan after this long (e.g. 2h): no new jobs start, running ones are killed and the exit code is 3")
+  nucleiCmd.Flags().Int("nice", 0, "Niceness (0-19) of the scan commands, so heavy parallel runs leave the box responsive")
+  nucleiCmd.Flags().String("max-memory", "", "Virtual memory limit per scan process, e.g. 2GB (applied with ulimit -v)")
+  nucleiCmd.Flags().Duration("max-cpu-time", 0, "CPU time limit per scan process, e.g. 10m (applied with ulimit -t)")
+  nucleiCmd.Flags().Int("max-output-lines-per-job", 0, "Kill a job once it printed this many lines, with a warning, so a runaway template can't fill the disk (0 for no limit)")
+  nucleiCmd.Flags().Duration("timeout", 0, "Kill a job that runs longer than this (e.g. 10m, 0 for no timeout)")
+  nucleiCmd.Flags().String("timeout-map", "", "Per-tech timeout overrides, e.g. \"confluence=15m,default=5m\"; a job with several techs gets the longest")
+  nucleiCmd.Flags().Duration("idle-warn", 0, "Warn with the list of running jobs if no job completes within this interval (e.g. 5m, 0 to disable)")
 }
\ No newline at end of file
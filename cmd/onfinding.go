@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Environment variable holding the finding line for --on-finding-exec
+const findingEnv = "VULNTECHFINDER_FINDING"
+
+// Findings queued for --on-finding-exec before new ones are dropped
+const findingQueueSize = 1000
+
+// findingEvent is a finding waiting for its --on-finding-exec command
+type findingEvent struct {
+	host    string
+	tech    string
+	finding string
+}
+
+// findingHook runs the --on-finding-exec command for each finding on its own goroutine, at most perSecond
+// commands per second, so slow automation never blocks the scan; findings beyond the queue are dropped, and so are
+// findings once --max-procs allows no more commands
+type findingHook struct {
+	template  string
+	perSecond int
+	dir       string
+	env       []string
+	procs     *procBudget
+	queue     chan findingEvent
+	done      chan struct{}
+	dropped   atomic.Int64
+	capped    atomic.Int64
+	mu        sync.Mutex // guards closed against fire from jobs still running after --shutdown-grace
+	closed    bool
+}
+
+// newFindingHook starts the hook worker, or returns nil (no-op) when template is empty
+func newFindingHook(template string, perSecond int, dir string, env []string, procs *procBudget) *findingHook {
+	if template == "" {
+		return nil
+	}
+	if perSecond < 1 {
+		perSecond = 1
+	}
+	h := &findingHook{
+		template:  template,
+		perSecond: perSecond,
+		dir:       dir,
+		env:       env,
+		procs:     procs,
+		queue:     make(chan findingEvent, findingQueueSize),
+		done:      make(chan struct{}),
+	}
+	go h.run()
+	return h
+}
+
+func (h *findingHook) run() {
+	defer close(h.done)
+	ticker := time.NewTicker(time.Second / time.Duration(h.perSecond))
+	defer ticker.Stop()
+
+	for event := range h.queue {
+		if !h.procs.take() {
+			h.capped.Add(1)
+			continue
+		}
+		<-ticker.C
+		// The finding comes from the scanned server and the host from the input, so both are substituted as one
+		// quoted word that can't run commands
+		cmdStr := strings.NewReplacer(placeholderHost, shellQuote(event.host), placeholderTech, event.tech, placeholderFinding, shellQuote(event.finding)).Replace(h.template)
+		env := append(append([]string(nil), h.env...), findingEnv+"="+event.finding)
+		if err := runHook(cmdStr, h.dir, env); err != nil {
+			fmt.Printf("Error running --on-finding-exec for %s: %s\n", event.host, err)
+		}
+	}
+}
+
+// fire queues the command for a finding without waiting for it
+func (h *findingHook) fire(host, tech, finding string) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	select {
+	case h.queue <- findingEvent{host: host, tech: tech, finding: finding}:
+	default:
+		h.dropped.Add(1)
+	}
+}
+
+// close waits for the queued commands to finish and reports findings that were dropped; later findings are ignored
+func (h *findingHook) close() {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	h.closed = true
+	close(h.queue)
+	h.mu.Unlock()
+	<-h.done
+	if dropped := h.dropped.Load(); dropped > 0 {
+		fmt.Printf("WARNING: --on-finding-exec queue was full, %d findings did not trigger the command\n", dropped)
+	}
+	if capped := h.capped.Load(); capped > 0 {
+		fmt.Printf("WARNING: --max-procs was reached, %d findings did not trigger --on-finding-exec\n", capped)
+	}
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// orderedOutput prints the terminal lines of parallel jobs in the order the jobs were dispatched, for
+// --ordered-output. Lines of the oldest unfinished job are printed as they come; those of later jobs are held
+// until every job before them finished, in memory up to limit bytes overall and past it in one temp file shared
+// by all jobs, which is removed again once nothing is left in it.
+type orderedOutput struct {
+	mu       sync.Mutex
+	print    func(string)
+	limit    int64
+	held     int64    // bytes of lines held in memory
+	spill    *os.File // lines past the memory limit, of any job
+	spillEnd int64    // size of the spill file
+	spilled  int64    // bytes of the spill file not printed yet
+	spillErr bool     // a spill error was reported
+	seq      int      // sequence number of the next job to dispatch
+	next     int      // sequence number of the job printing directly
+	jobs     map[int]*orderedJob
+}
+
+// orderedJob holds the lines of a job that can't be printed yet
+type orderedJob struct {
+	lines []string
+	spans []spillSpan // lines past the memory limit, in order after lines
+	done  bool
+}
+
+// spillSpan is a run of a job's lines in the spill file
+type spillSpan struct {
+	offset int64
+	length int64
+}
+
+// newOrderedOutput returns nil when enabled is false, so jobs print directly
+func newOrderedOutput(enabled bool, emitLine func(string), limit int64) *orderedOutput {
+	if !enabled {
+		return nil
+	}
+	return &orderedOutput{print: emitLine, limit: limit, jobs: make(map[int]*orderedJob)}
+}
+
+// begin assigns the next sequence number; call it in dispatch order
+func (o *orderedOutput) begin() int {
+	if o == nil {
+		return 0
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.seq++
+	return o.seq - 1
+}
+
+// println prints line of job seq now if it is the oldest unfinished job, and holds it otherwise
+func (o *orderedOutput) println(seq int, line string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if seq == o.next {
+		o.print(line)
+		return
+	}
+
+	job := o.job(seq)
+	if len(job.spans) == 0 && (o.limit <= 0 || o.held+int64(len(line)) <= o.limit) {
+		job.lines = append(job.lines, line)
+		o.held += int64(len(line))
+		return
+	}
+	if err := o.spillLine(job, line); err != nil {
+		// Print the line out of order rather than lose it or hold it in memory past the limit
+		if !o.spillErr {
+			o.spillErr = true
+			fmt.Printf("Error holding --ordered-output lines in a temp file, printing them out of order: %s\n", err)
+		}
+		o.print(line)
+	}
+}
+
+// spillLine appends line to the spill file, creating the file on first use
+func (o *orderedOutput) spillLine(job *orderedJob, line string) error {
+	if o.spill == nil {
+		spill, err := os.CreateTemp("", "vulntechfinder-ordered-*")
+		if err != nil {
+			return err
+		}
+		o.spill, o.spillEnd = spill, 0
+	}
+	n, err := o.spill.WriteAt([]byte(line+"\n"), o.spillEnd)
+	if err != nil {
+		return err
+	}
+	// Extend the job's last span when nothing of other jobs was written after it
+	if last := len(job.spans) - 1; last >= 0 && job.spans[last].offset+job.spans[last].length == o.spillEnd {
+		job.spans[last].length += int64(n)
+	} else {
+		job.spans = append(job.spans, spillSpan{o.spillEnd, int64(n)})
+	}
+	o.spillEnd += int64(n)
+	o.spilled += int64(n)
+	return nil
+}
+
+// finish marks job seq as done and prints the held lines of the jobs that are next in order
+func (o *orderedOutput) finish(seq int) {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.job(seq).done = true
+
+	for {
+		job := o.jobs[o.next]
+		if job == nil {
+			return
+		}
+		o.flush(job)
+		if !job.done {
+			// The job is the oldest unfinished one now; its next lines print directly
+			return
+		}
+		delete(o.jobs, o.next)
+		o.next++
+	}
+}
+
+// drain prints the lines held for every job in dispatch order, for a shutdown that doesn't wait for all jobs
+func (o *orderedOutput) drain() {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	seqs := make([]int, 0, len(o.jobs))
+	for seq := range o.jobs {
+		seqs = append(seqs, seq)
+	}
+	sort.Ints(seqs)
+	for _, seq := range seqs {
+		o.flush(o.jobs[seq])
+	}
+}
+
+// job returns the state of job seq, creating it on first use
+func (o *orderedOutput) job(seq int) *orderedJob {
+	job := o.jobs[seq]
+	if job == nil {
+		job = &orderedJob{}
+		o.jobs[seq] = job
+	}
+	return job
+}
+
+// flush prints the lines held for job, from memory and then from the spill file
+func (o *orderedOutput) flush(job *orderedJob) {
+	for _, line := range job.lines {
+		o.print(line)
+		o.held -= int64(len(line))
+	}
+	job.lines = nil
+
+	for _, span := range job.spans {
+		reader := bufio.NewReader(io.NewSectionReader(o.spill, span.offset, span.length))
+		for {
+			line, err := reader.ReadString('\n')
+			if line != "" {
+				o.print(strings.TrimSuffix(line, "\n"))
+			}
+			if err != nil {
+				if err != io.EOF && !o.spillErr {
+					o.spillErr = true
+					fmt.Printf("Error reading --ordered-output lines back from the temp file: %s\n", err)
+				}
+				break
+			}
+		}
+		o.spilled -= span.length
+	}
+	job.spans = nil
+
+	// Remove the spill file once every line in it was printed; it is created again when needed
+	if o.spill != nil && o.spilled == 0 {
+		o.spill.Close()
+		os.Remove(o.spill.Name())
+		o.spill = nil
+	}
+}
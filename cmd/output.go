@@ -0,0 +1,335 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/rix4uni/vulntechfinder/banner"
+)
+
+// outputWriter appends lines to the --output file, serializing writes from the workers and rotating the file
+// to name.1, name.2, ... once it would grow past maxSize (0 disables rotation). With daily, the file lives
+// under a YYYY/MM/DD/ directory next to the configured path and moves to the new day's directory at midnight.
+// A path ending in .zst is zstd compressed, each opening of the file appending a frame of its own; the size
+// limit then counts uncompressed bytes.
+type outputWriter struct {
+	mu      sync.Mutex
+	base    string // --output as given
+	path    string // file being written: base, or base under today's directory with daily
+	daily   bool
+	header  string // written at the start of every new file, e.g. the --parse-findings csv header
+	maxSize int64
+	file    *os.File
+	out     io.Writer     // file, or the zstd encoder writing to it
+	zst     *zstd.Encoder // set for .zst output
+	opened  bool          // a file was created, false for a lazy writer that got no lines yet
+	size    int64
+	verify  bool // --validate-output: read every write back and check it parses as JSON
+	closed  bool // set by Close, so jobs still running after --shutdown-grace can't reopen the file
+}
+
+// compressedOutput reports whether path is written zstd compressed
+func compressedOutput(path string) bool {
+	return strings.HasSuffix(path, ".zst")
+}
+
+// errInvalidOutput is returned by WriteString when --validate-output finds the written data corrupt
+var errInvalidOutput = errors.New("written output failed validation")
+
+// errOutputClosed is returned by WriteString after Close
+var errOutputClosed = errors.New("output already closed")
+
+// openOutputWriter opens path, or today's file for it with daily, for appending
+func openOutputWriter(path string, maxSize int64, daily bool) (*outputWriter, error) {
+	w := &outputWriter{base: path, daily: daily, maxSize: maxSize}
+	w.path = w.currentPath(time.Now())
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// openLazyOutputWriter is openOutputWriter for --only-nonempty: the file is only created by the first write,
+// so a run without results leaves no empty output behind
+func openLazyOutputWriter(path string, maxSize int64, daily bool) *outputWriter {
+	w := &outputWriter{base: path, daily: daily, maxSize: maxSize}
+	w.path = w.currentPath(time.Now())
+	return w
+}
+
+// currentPath returns the file to write at now: base, or base/../YYYY/MM/DD/name with daily
+func (w *outputWriter) currentPath(now time.Time) string {
+	if !w.daily {
+		return w.base
+	}
+	return filepath.Join(filepath.Dir(w.base), now.Format("2006/01/02"), filepath.Base(w.base))
+}
+
+// Path returns the file currently written
+func (w *outputWriter) Path() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.path
+}
+
+// setHeader writes header to the current file if it is empty, and to every file started later
+func (w *outputWriter) setHeader(header string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.header = header
+	return w.writeHeader()
+}
+
+func (w *outputWriter) writeHeader() error {
+	if w.file == nil || w.header == "" || w.size > 0 {
+		return nil
+	}
+	n, err := io.WriteString(w.out, w.header)
+	w.size += int64(n)
+	return err
+}
+
+func (w *outputWriter) open() error {
+	if w.daily {
+		if err := os.MkdirAll(filepath.Dir(w.path), 0755); err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(w.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	w.file = file
+	w.out = file
+	w.opened = true
+	w.size = info.Size()
+	if compressedOutput(w.path) {
+		// The size limit counts uncompressed bytes, so count those of the frames already in the file
+		if w.size > 0 {
+			w.size = uncompressedSize(w.path)
+		}
+		if w.zst, err = zstd.NewWriter(file); err != nil {
+			file.Close()
+			w.file = nil
+			return err
+		}
+		w.out = w.zst
+	}
+	return w.writeHeader()
+}
+
+// uncompressedSize returns the number of bytes the zstd frames in path decompress to. Decoding stops at the first
+// corrupt or unfinished frame (e.g. of a run that crashed), counting what was decoded until then.
+func uncompressedSize(path string) int64 {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+	decoder, err := zstd.NewReader(file)
+	if err != nil {
+		return 0
+	}
+	defer decoder.Close()
+	n, _ := io.Copy(io.Discard, decoder)
+	return n
+}
+
+// closeFile flushes the zstd frame, if any, and closes the current file
+func (w *outputWriter) closeFile() error {
+	if w.file == nil {
+		return nil
+	}
+	var err error
+	if w.zst != nil {
+		err = w.zst.Close()
+		w.zst = nil
+	}
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	w.file = nil
+	return err
+}
+
+// validateJSON makes every later write be read back from the file and checked to hold complete JSON values,
+// for --validate-output. It is a no-op on a nil writer and on .zst output, whose compressed bytes can't be read
+// back at the uncompressed offsets (the commands reject --validate-output with a .zst path).
+func (w *outputWriter) validateJSON() {
+	if w == nil || compressedOutput(w.base) {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.verify = true
+}
+
+// WriteString appends s, switching to the new day's file or rotating first if it would push the file past the size limit
+func (w *outputWriter) WriteString(s string) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, errOutputClosed
+	}
+	if path := w.currentPath(time.Now()); path != w.path {
+		w.closeFile()
+		w.path = path
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	} else if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if w.maxSize > 0 && w.size > 0 && w.size+int64(len(s)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	offset := w.size
+	n, err := io.WriteString(w.out, s)
+	w.size += int64(n)
+	if err == nil && w.verify {
+		err = w.check(offset, s)
+	}
+	return n, err
+}
+
+// check reads the len(s) bytes written at offset back from disk and verifies they match s and parse as JSON
+func (w *outputWriter) check(offset int64, s string) error {
+	file, err := os.Open(w.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	written := make([]byte, len(s))
+	if _, err := file.ReadAt(written, offset); err != nil {
+		return fmt.Errorf("%w: reading back: %s", errInvalidOutput, err)
+	}
+	if string(written) != s {
+		return fmt.Errorf("%w: %s holds different bytes than were written at offset %d", errInvalidOutput, w.path, offset)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(written))
+	for {
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%w: %s at offset %d is not valid JSON: %s", errInvalidOutput, w.path, offset, err)
+		}
+	}
+}
+
+// rotate shifts name.N to name.N+1, moves the current file to name.1 and starts a new one
+func (w *outputWriter) rotate() error {
+	w.closeFile()
+
+	last := 0
+	for fileExists(fmt.Sprintf("%s.%d", w.path, last+1)) {
+		last++
+	}
+	for i := last; i >= 1; i-- {
+		if err := os.Rename(fmt.Sprintf("%s.%d", w.path, i), fmt.Sprintf("%s.%d", w.path, i+1)); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(w.path, w.path+".1"); err != nil {
+		return err
+	}
+	return w.open()
+}
+
+// Written reports whether the file exists, false for a lazy writer that got no lines yet
+func (w *outputWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.opened
+}
+
+// Close closes the current file; later writes fail with errOutputClosed
+func (w *outputWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	return w.closeFile()
+}
+
+// outputHeader describes the run for --output-include-command: a "#" comment line, or a JSON object when the
+// output holds JSON records
+func outputHeader(command string, asJSON bool) string {
+	started := time.Now().Format(time.RFC3339)
+	if asJSON {
+		data, _ := json.MarshalIndent(map[string]string{"vulntechfinder": banner.Version(), "command": command, "started": started}, "", "  ")
+		return string(data) + "\n"
+	}
+	return fmt.Sprintf("# vulntechfinder %s started %s: %s\n", banner.Version(), started, command)
+}
+
+// parseSize parses sizes like "500", "64KB", "100MB" or "1G" into bytes
+func parseSize(input string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(input))
+	if s == "" || s == "0" {
+		return 0, nil
+	}
+
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		value  int64
+	}{{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"B", 1}} {
+		if strings.HasSuffix(s, unit.suffix) {
+			multiplier = unit.value
+			s = strings.TrimSuffix(s, unit.suffix)
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", input)
+	}
+	return n * multiplier, nil
+}
+
+// outputTimestamp in an --output path is replaced with the run's start time, e.g. results/scan-{timestamp}.txt
+const outputTimestamp = "{timestamp}"
+
+// expandOutputPath fills the {timestamp} placeholder of an --output path
+func expandOutputPath(path string, start time.Time) string {
+	return strings.Replace(path, outputTimestamp, start.Format("20060102-150405"), -1)
+}
+
+// linkLatest points latest<ext> in the directory of the --output path base at target, the file the run wrote,
+// for --output-symlink-latest. The link is relative and swapped in with a rename, so readers never see it missing.
+func linkLatest(base, target string) error {
+	latest := filepath.Join(filepath.Dir(base), "latest"+filepath.Ext(base))
+	relative, err := filepath.Rel(filepath.Dir(base), target)
+	if err != nil {
+		return err
+	}
+	tmp := latest + ".tmp"
+	os.Remove(tmp)
+	if err := os.Symlink(relative, tmp); err != nil {
+		return err
+	}
+	return os.Rename(tmp, latest)
+}
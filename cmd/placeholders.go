@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Placeholders substituted into command templates. Code doing a substitution must use these constants,
+// and every constant must be listed in placeholderRegistry so `placeholders` never drifts from the implementation.
+const (
+	placeholderTech          = "{tech}"
+	placeholderHost          = "{host}"
+	placeholderTechTemplates = "{tech-templates}"
+	placeholderUA            = "{ua}"
+	placeholderFinding       = "{finding}"
+)
+
+// placeholder describes a supported template placeholder
+type placeholder struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Commands    []string `json:"commands"`
+}
+
+// placeholderRegistry lists every placeholder and the subcommands that substitute it
+var placeholderRegistry = []placeholder{
+	{placeholderTech, "Technology name(s): comma-separated tags (or a -tc condition) for nuclei, the wordlist path or tech name for httpx", []string{"nuclei", "httpx"}},
+	{placeholderHost, "Host being scanned (newline-separated hosts for nuclei --group-by-tech), inserted single-quoted", []string{"nuclei", "httpx"}},
+	{placeholderTechTemplates, "Comma-separated <templates-dir>/<tech>/ folders of the job's techs", []string{"nuclei"}},
+	{placeholderUA, "Random User-Agent picked per job with --random-ua or --ua-file (also exported as VULNTECHFINDER_UA), inserted single-quoted; empty without them", []string{"nuclei", "httpx"}},
+	{placeholderFinding, "Finding line that triggered --on-finding-exec (also exported as VULNTECHFINDER_FINDING)", []string{"nuclei"}},
+	{placeholderFile + "<glob>}", "First file matching the glob after {host} and {tech} in it are filled in, inserted single-quoted, e.g. {file:configs/{host}.yaml}; jobs without a match are skipped", []string{"nuclei", "httpx"}},
+	{"{<var>}", "Value of a --var name=value flag, the same for every job, e.g. {tpl} with --var tpl=~/mytemplates", []string{"nuclei", "httpx"}},
+	{"{<field>}", "Any other field of the input JSON record, e.g. {status} or {title}, with --json-fields; inserted single-quoted", []string{"nuclei", "httpx"}},
+}
+
+// placeholdersCmd represents the placeholders command
+var placeholdersCmd = &cobra.Command{
+	Use:   "placeholders",
+	Short: "List the placeholders supported in command templates and the subcommands that substitute them.",
+	Long: `The 'placeholders' command prints each supported placeholder, the subcommands that support it and its meaning, one per line separated by tabs, or as JSON with --json.
+
+Examples:
+  vulntechfinder placeholders
+  vulntechfinder placeholders --json
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		if asJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(placeholderRegistry); err != nil {
+				fmt.Printf("Error encoding placeholders: %s\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		for _, p := range placeholderRegistry {
+			fmt.Printf("%s\t%s\t%s\n", p.Name, strings.Join(p.Commands, ","), p.Description)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(placeholdersCmd)
+
+	placeholdersCmd.Flags().Bool("json", false, "Print the placeholders as a JSON array")
+}
@@ -0,0 +1,30 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// killProcessGroupOnCancel runs the child in its own process group and kills the whole group when its
+// context is done, so programs started by the "sh -c" wrapper don't outlive a timeout while holding the output pipes
+func killProcessGroupOnCancel(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
+
+// setProcessGroup runs the child in its own process group, keeping any other process attributes
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// reniceProcessGroup sets the niceness of the process group led by pid
+func reniceProcessGroup(pid, nice int) error {
+	return syscall.Setpriority(syscall.PRIO_PGRP, pid, nice)
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package cmd
+
+import "os/exec"
+
+// killProcessGroupOnCancel is a no-op on Windows, where the default cancel kills the child only
+func killProcessGroupOnCancel(cmd *exec.Cmd) {}
+
+// setProcessGroup is a no-op on Windows
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// reniceProcessGroup is a no-op on Windows, where --nice is not supported
+func reniceProcessGroup(pid, nice int) error { return nil }
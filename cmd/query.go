@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// queryCmd represents the query command
+var queryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Print the hosts from techfinder JSON read from stdin that run the given technologies.",
+	Long: `The 'query' command reads JSON (objects with {"host":..., "tech":[...]}) from stdin and prints each host whose normalized tech list contains any of the --tech values, or all of them with --all.
+
+Examples:
+  cat techfinder-output.json | vulntechfinder query --tech jira --tech confluence
+  cat techfinder-output.json | vulntechfinder query --tech php,wordpress --all
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		techFlags, _ := cmd.Flags().GetStringArray("tech")
+		all, _ := cmd.Flags().GetBool("all")
+
+		var wanted []string
+		for _, value := range techFlags {
+			for _, tech := range strings.Split(value, ",") {
+				if tech = strings.ToLower(strings.TrimSpace(tech)); tech != "" {
+					wanted = append(wanted, tech)
+				}
+			}
+		}
+
+		if len(wanted) == 0 {
+			fmt.Println("Usage: vulntechfinder query --tech <name> [--tech <name>] [--all]")
+			os.Exit(1)
+		}
+
+		decoder := json.NewDecoder(os.Stdin)
+		for {
+			var techData TechData
+			if err := decoder.Decode(&techData); err == io.EOF {
+				break
+			} else if err != nil {
+				fmt.Printf("Error decoding JSON: %s\n", err)
+				os.Exit(1)
+			}
+
+			techs := normalizeTechs(techData.Tech, "first")
+			matches := 0
+			for _, tech := range wanted {
+				if contains(techs, tech) {
+					matches++
+				}
+			}
+
+			if (all && matches == len(wanted)) || (!all && matches > 0) {
+				fmt.Println(techData.Host)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(queryCmd)
+
+	queryCmd.Flags().StringArray("tech", nil, "Technology to look for, repeatable or comma-separated")
+	queryCmd.Flags().Bool("all", false, "Only print hosts running all of the --tech values instead of any of them")
+	queryCmd.RegisterFlagCompletionFunc("tech", completeTechNames)
+}
+
+// printPlanEntry prints the {"host":..., "tech":[...]} record a host would be scanned with, for --print-plan
+func printPlanEntry(host string, techs []string) {
+	data, err := json.Marshal(TechData{Host: host, Tech: techs})
+	if err != nil {
+		return
+	}
+	fmt.Println(string(data))
+}
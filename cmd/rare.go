@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// rareFirstRecords buffers the JSON records read from r and reorders them for --rare-first, so that the techs
+// found on the fewest hosts are scanned first and an early stop still covers the uncommon ones. Each record's
+// techs are sorted rarest first and the records by their rarest tech. Records stay whole, so a host still makes
+// one record for --limit and --resume; records without a tech list keep their relative order at the end.
+// Only techs that pass filters are counted, so excluded and noise techs don't decide the order; they sort after
+// the scanned techs of their record, and records with none scanned go to the end. With caseSensitive, names
+// differing only in case are counted apart.
+func rareFirstRecords(r io.Reader, segment string, caseSensitive bool, filters techFilters) (io.Reader, error) {
+	type rareRecord struct {
+		fields map[string]json.RawMessage
+		techs  []string
+	}
+
+	var records []rareRecord
+	hosts := make(map[string]int)     // hosts per scanned tech name
+	firstSeen := make(map[string]int) // input position of each tech name, keeping equally common techs together
+	decoder := json.NewDecoder(r)
+	for {
+		var fields map[string]json.RawMessage
+		if err := decoder.Decode(&fields); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		var techs []string
+		json.Unmarshal(fields["tech"], &techs)
+		counted := make(map[string]bool)
+		for _, t := range techs {
+			name, version := splitTech(t, segment)
+			if name = techCase(name, caseSensitive); !counted[name] && filters.scans(name, version) {
+				counted[name] = true
+				hosts[name]++
+				if _, ok := firstSeen[name]; !ok {
+					firstSeen[name] = len(firstSeen)
+				}
+			}
+		}
+		records = append(records, rareRecord{fields, techs})
+	}
+
+	// Order tech entries by how many hosts have them, equally common ones in the order they were first seen and
+	// filtered out ones last
+	less := func(a, b string) bool {
+		nameA, nameB := rareKey(a, segment, caseSensitive), rareKey(b, segment, caseSensitive)
+		countA, scannedA := hosts[nameA]
+		countB, scannedB := hosts[nameB]
+		if scannedA != scannedB {
+			return scannedA
+		}
+		if countA != countB {
+			return countA < countB
+		}
+		return firstSeen[nameA] < firstSeen[nameB]
+	}
+	// scanned reports whether a record with its techs sorted has any tech that is scanned
+	scanned := func(record rareRecord) bool {
+		if len(record.techs) == 0 {
+			return false
+		}
+		_, ok := hosts[rareKey(record.techs[0], segment, caseSensitive)]
+		return ok
+	}
+
+	for _, record := range records {
+		sort.SliceStable(record.techs, func(i, j int) bool { return less(record.techs[i], record.techs[j]) })
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		if !scanned(records[i]) || !scanned(records[j]) {
+			return scanned(records[i]) && !scanned(records[j])
+		}
+		return less(records[i].techs[0], records[j].techs[0])
+	})
+
+	var lines [][]byte
+	for _, record := range records {
+		if record.techs != nil {
+			techs, err := json.Marshal(record.techs)
+			if err != nil {
+				return nil, err
+			}
+			record.fields["tech"] = techs
+		}
+		line, err := json.Marshal(record.fields)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return bytes.NewReader(bytes.Join(lines, []byte("\n"))), nil
+}
+
+// rareKey is the name a tech entry is counted under for --rare-first, without its version and lowercased unless
+// caseSensitive
+func rareKey(t, segment string, caseSensitive bool) string {
+	name, _ := splitTech(t, segment)
+	return techCase(name, caseSensitive)
+}
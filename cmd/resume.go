@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"sync"
+)
+
+// resumeLog records completed host|tech jobs in an append-only file so an interrupted run can skip them.
+// Every record is synced to disk, so a crash loses at most the jobs that were in flight.
+// With --resume-granularity host it records hosts instead, once every job expected for the host has completed.
+// All methods are no-ops on a nil *resumeLog so callers don't need to check whether --resume is set.
+type resumeLog struct {
+	mu      sync.Mutex
+	file    *os.File
+	done    map[string]bool
+	byHost  bool
+	pending map[string]int // with byHost, jobs of each host expected but not completed yet
+}
+
+// Supported values for --resume-granularity
+var resumeGranularities = []string{"tech", "host"}
+
+// resumeKey identifies a job in the resume log
+func resumeKey(host, tech string) string {
+	return host + "|" + tech
+}
+
+// openResumeLog loads the completed jobs from path and opens it for appending; byHost keys the log on hosts
+func openResumeLog(path string, byHost bool) (*resumeLog, error) {
+	r := &resumeLog{done: make(map[string]bool), byHost: byHost, pending: make(map[string]int)}
+
+	if existing, err := os.Open(path); err == nil {
+		scanner := bufio.NewScanner(existing)
+		for scanner.Scan() {
+			if key := strings.TrimSpace(scanner.Text()); key != "" {
+				r.done[key] = true
+			}
+		}
+		existing.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	r.file = file
+	return r, nil
+}
+
+// has reports whether the job of host and tech, or the whole host with host granularity, was completed in a previous run
+func (r *resumeLog) has(host, tech string) bool {
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.byHost {
+		return r.done[host]
+	}
+	return r.done[resumeKey(host, tech)]
+}
+
+// expect announces that many more jobs of host, with host granularity. All jobs of a host must be expected before
+// the first of them can complete, so a host isn't recorded while some of its techs are still to be dispatched.
+func (r *resumeLog) expect(host string, jobs int) {
+	if r == nil || !r.byHost {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending[host] += jobs
+}
+
+// record appends the completed job of host and tech and syncs it to disk. With host granularity it counts one
+// expected job of host as done, and appends the host once none are left.
+func (r *resumeLog) record(host, tech string) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	if r.byHost {
+		return r.finishHost(host)
+	}
+	return r.write(resumeKey(host, tech))
+}
+
+// release drops a hold taken with expect(host, 1) once all jobs of host were dispatched, recording the host
+// if they already completed. It is a no-op without host granularity.
+func (r *resumeLog) release(host string) error {
+	if r == nil || !r.byHost {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	return r.finishHost(host)
+}
+
+// finishHost counts one expected job of host as done and records the host once none are left
+func (r *resumeLog) finishHost(host string) error {
+	if r.pending[host]--; r.pending[host] > 0 {
+		return nil
+	}
+	delete(r.pending, host)
+	return r.write(host)
+}
+
+func (r *resumeLog) write(key string) error {
+	r.done[key] = true
+	if _, err := r.file.WriteString(key + "\n"); err != nil {
+		return err
+	}
+	return r.file.Sync()
+}
+
+// close syncs and closes the log; later records are dropped
+func (r *resumeLog) close() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file != nil {
+		r.file.Sync()
+		r.file.Close()
+		r.file = nil
+	}
+}
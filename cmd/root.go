@@ -128,8 +128,35 @@ func getLatestVersion() (string, error) {
 	return "", fmt.Errorf("version not found in response")
 }
 
+// Commands that skip the banner because their stdout is consumed by shells or scripts
+var bannerlessCommands = map[string]bool{
+	cobra.ShellCompRequestCmd:       true,
+	cobra.ShellCompNoDescRequestCmd: true,
+	"techs":                         true,
+	"placeholders":                  true,
+	"commands":                      true,
+	"query":                         true,
+}
+
+// silentRequested reports whether args turn on --silent, which is checked before flags are parsed so the
+// banner can be skipped too
+func silentRequested(args []string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if arg == "--silent" || arg == "--silent=true" {
+			return true
+		}
+	}
+	return false
+}
+
 func Execute() {
-	banner.PrintBanner() // Print banner at the start
+	// Print banner at the start, except for commands whose output is meant to be parsed
+	if (len(os.Args) < 2 || !bannerlessCommands[os.Args[1]]) && !silentRequested(os.Args[1:]) {
+		banner.PrintBanner()
+	}
 	err := rootCmd.Execute()
 	if err != nil {
 		os.Exit(1)
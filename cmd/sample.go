@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/rand"
+)
+
+// techSampler keeps at most n hosts per tech for --sample-per-tech, taking hosts in the order they are offered
+type techSampler struct {
+	n      int
+	counts map[string]int
+}
+
+// newTechSampler returns a sampler keeping n hosts per tech, or nil (keep everything) when n is not positive
+func newTechSampler(n int) *techSampler {
+	if n <= 0 {
+		return nil
+	}
+	return &techSampler{n: n, counts: make(map[string]int)}
+}
+
+// keep reports whether another host may be scanned for tech and counts it if so
+func (s *techSampler) keep(tech string) bool {
+	if s == nil {
+		return true
+	}
+	if s.counts[tech] >= s.n {
+		return false
+	}
+	s.counts[tech]++
+	return true
+}
+
+// shuffleRecords buffers the JSON records read from r and returns them in random order, so that taking the
+// first hosts per tech with --sample-random yields a random sample
+func shuffleRecords(r io.Reader) (io.Reader, error) {
+	var records [][]byte
+	decoder := json.NewDecoder(r)
+	for {
+		var record json.RawMessage
+		if err := decoder.Decode(&record); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		records = append(records, []byte(record))
+	}
+
+	rand.Shuffle(len(records), func(i, j int) { records[i], records[j] = records[j], records[i] })
+	return bytes.NewReader(bytes.Join(records, []byte("\n"))), nil
+}
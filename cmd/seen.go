@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// seenDB remembers when each host|tech pair was last scanned, across runs, in a file of "<unix time>\t<host|tech>"
+// lines for --seen-db. Pairs scanned less than within ago count as recent and are skipped with --skip-seen-within.
+// All methods are no-ops on a nil *seenDB so callers don't need to check whether --seen-db is set.
+type seenDB struct {
+	mu     sync.Mutex
+	file   *os.File
+	within time.Duration
+	seen   map[string]time.Time
+}
+
+// openSeenDB loads path, compacts it to the latest time of each pair and opens it for appending
+func openSeenDB(path string, within time.Duration) (*seenDB, error) {
+	d := &seenDB{within: within, seen: make(map[string]time.Time)}
+
+	if existing, err := os.Open(path); err == nil {
+		scanner := bufio.NewScanner(existing)
+		for scanner.Scan() {
+			fields := strings.SplitN(strings.TrimSpace(scanner.Text()), "\t", 2)
+			if len(fields) != 2 {
+				continue
+			}
+			unix, err := strconv.ParseInt(fields[0], 10, 64)
+			if err != nil {
+				continue
+			}
+			if at := time.Unix(unix, 0); at.After(d.seen[fields[1]]) {
+				d.seen[fields[1]] = at
+			}
+		}
+		existing.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Rewrite the file with one line per pair so it doesn't grow with every run
+	var compacted strings.Builder
+	for key, at := range d.seen {
+		fmt.Fprintf(&compacted, "%d\t%s\n", at.Unix(), key)
+	}
+	if err := os.WriteFile(path+".tmp", []byte(compacted.String()), 0644); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	d.file = file
+	return d, nil
+}
+
+// recent reports whether key was scanned less than --skip-seen-within ago
+func (d *seenDB) recent(key string) bool {
+	if d == nil || d.within <= 0 {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	at, ok := d.seen[key]
+	return ok && time.Since(at) < d.within
+}
+
+// record stores that key was scanned now
+func (d *seenDB) record(key string) error {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.file == nil {
+		return nil
+	}
+	now := time.Now()
+	d.seen[key] = now
+	_, err := fmt.Fprintf(d.file, "%d\t%s\n", now.Unix(), key)
+	return err
+}
+
+// close closes the database file; later records are dropped
+func (d *seenDB) close() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.file != nil {
+		d.file.Close()
+		d.file = nil
+	}
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Severity levels nuclei prints in brackets on each finding line, from lowest to highest
+var nucleiSeverities = []string{"unknown", "info", "low", "medium", "high", "critical"}
+
+// severityRank returns the position of severity in nucleiSeverities, or -1 if it isn't a known severity
+func severityRank(severity string) int {
+	for i, s := range nucleiSeverities {
+		if s == strings.ToLower(severity) {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseSeverity returns the severity token of a nuclei finding line such as "[id] [http] [high] https://...",
+// or "unknown" if no bracketed severity is found. Color codes of nuclei's default colored output are ignored.
+func parseSeverity(line string) string {
+	line = ansiColorRegex.ReplaceAllString(line, "")
+	for _, field := range strings.Fields(line) {
+		if !strings.HasPrefix(field, "[") || !strings.HasSuffix(field, "]") {
+			continue
+		}
+		token := strings.ToLower(strings.Trim(field, "[]"))
+		if contains(nucleiSeverities, token) {
+			return token
+		}
+	}
+	return "unknown"
+}
+
+// severityFiles appends finding lines to one file per severity, opened on first use
+type severityFiles struct {
+	mu     sync.Mutex
+	base   string
+	files  map[string]*os.File
+	closed bool
+}
+
+func newSeverityFiles(base string) *severityFiles {
+	if base == "" {
+		base = "output.txt"
+	}
+	return &severityFiles{base: base, files: make(map[string]*os.File)}
+}
+
+// severityPath inserts the severity before the extension of base, e.g. "nuclei-output.txt" -> "nuclei-output-high.txt"
+func severityPath(base, severity string) string {
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "-" + severity + ext
+}
+
+// write appends line to the file for severity
+func (s *severityFiles) write(severity, line string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return errOutputClosed
+	}
+	file, ok := s.files[severity]
+	if !ok {
+		var err error
+		file, err = os.OpenFile(severityPath(s.base, severity), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		s.files[severity] = file
+	}
+	_, err := file.WriteString(line + "\n")
+	return err
+}
+
+// close closes every opened severity file; later writes fail with errOutputClosed
+func (s *severityFiles) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for _, file := range s.files {
+		file.Close()
+	}
+}
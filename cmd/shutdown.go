@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Exit code after an interrupt, as shells report for SIGINT
+const exitInterrupted = 130
+
+// interruptHandler turns the first Ctrl-C (or SIGTERM) into a graceful shutdown: the scan context is cancelled
+// so no new jobs start and running commands stop, and the jobs get up to grace to write the output they already
+// produced before the outputs are closed. A second signal exits at once.
+type interruptHandler struct {
+	grace    time.Duration
+	signaled chan struct{}
+}
+
+// handleInterrupt starts watching for SIGINT and SIGTERM, calling cancel on the first one
+func handleInterrupt(cancel context.CancelFunc, grace time.Duration) *interruptHandler {
+	h := &interruptHandler{grace: grace, signaled: make(chan struct{})}
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		fmt.Printf("Interrupted, stopping jobs and writing their output (up to %s; interrupt again to quit now)\n", grace)
+		close(h.signaled)
+		cancel()
+		<-sigs
+		os.Exit(exitInterrupted)
+	}()
+	return h
+}
+
+// interrupted reports whether the run was interrupted
+func (h *interruptHandler) interrupted() bool {
+	select {
+	case <-h.signaled:
+		return true
+	default:
+		return false
+	}
+}
+
+// wait waits for the jobs of wg, for at most the grace period once the run is interrupted, and reports
+// whether they all finished
+func (h *interruptHandler) wait(wg *sync.WaitGroup) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-h.signaled:
+	}
+	select {
+	case <-done:
+		return true
+	case <-time.After(h.grace):
+		fmt.Printf("Jobs still running after the %s --shutdown-grace, closing the outputs without them\n", h.grace)
+		return false
+	}
+}
@@ -0,0 +1,26 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// dumpStatsOnSignal writes the scan progress to stderr every time the process receives SIGUSR1, e.g.
+// kill -USR1 <pid>, without stopping the run, until stop is closed
+func (a *activeJobs) dumpStatsOnSignal(stop <-chan struct{}, extra func() string) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-signals:
+			a.dumpStats(os.Stderr, extra)
+		}
+	}
+}
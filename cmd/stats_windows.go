@@ -0,0 +1,6 @@
+//go:build windows
+
+package cmd
+
+// dumpStatsOnSignal is a no-op on Windows, which has no SIGUSR1
+func (a *activeJobs) dumpStatsOnSignal(stop <-chan struct{}, extra func() string) {}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+)
+
+// stdoutWriter prints job output lines. Once stdout turns out to be a closed pipe (e.g. `| head`) it cancels
+// the scan so running commands are stopped and the run ends quietly, instead of the process dying on SIGPIPE
+// with its children left running.
+type stdoutWriter struct {
+	once     sync.Once
+	cancel   context.CancelFunc
+	closed   atomic.Bool
+	mu       sync.Mutex
+	jobsDone bool // set by endJobs, after which job lines are dropped
+}
+
+// newStdoutWriter makes writes to a broken stdout pipe return EPIPE instead of killing the process,
+// and calls cancel on the first such write
+func newStdoutWriter(cancel context.CancelFunc) *stdoutWriter {
+	signal.Notify(make(chan os.Signal, 1), syscall.SIGPIPE)
+	return &stdoutWriter{cancel: cancel}
+}
+
+// println prints line, dropping it once the reader of stdout has gone away
+func (w *stdoutWriter) println(line string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.print(line)
+}
+
+// jobLine prints a line of job output like println, unless endJobs was called
+func (w *stdoutWriter) jobLine(line string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.jobsDone {
+		return
+	}
+	w.print(line)
+}
+
+// endJobs drops the lines of jobs still running after --shutdown-grace, so none are printed after the summary
+func (w *stdoutWriter) endJobs() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.jobsDone = true
+}
+
+func (w *stdoutWriter) print(line string) {
+	if w.closed.Load() {
+		return
+	}
+	if _, err := fmt.Println(line); errors.Is(err, syscall.EPIPE) {
+		w.once.Do(func() {
+			w.closed.Store(true)
+			w.cancel()
+		})
+	}
+}
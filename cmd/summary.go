@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Exit code used with --fail-on-findings when the scan reported at least one finding
+const exitFindings = 2
+
+// scanTally counts the jobs run and the findings written, the distinct hosts scanned and those findings were on
+type scanTally struct {
+	jobs     atomic.Int64
+	findings atomic.Int64
+	mu       sync.Mutex
+	hosts    map[string]bool
+	scanned  map[string]bool
+}
+
+func newScanTally() *scanTally {
+	return &scanTally{hosts: make(map[string]bool), scanned: make(map[string]bool)}
+}
+
+// job counts a started job over hosts
+func (t *scanTally) job(hosts []string) {
+	t.jobs.Add(1)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, host := range hosts {
+		t.scanned[host] = true
+	}
+}
+
+// finding counts a finding on host
+func (t *scanTally) finding(host string) {
+	t.findings.Add(1)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.hosts[host] = true
+}
+
+// summary returns the final "found N findings across M of S scanned hosts" line
+func (t *scanTally) summary() string {
+	t.mu.Lock()
+	hosts, scanned := len(t.hosts), len(t.scanned)
+	t.mu.Unlock()
+	return fmt.Sprintf("found %d findings across %d of %d scanned hosts (%d jobs)", t.findings.Load(), hosts, scanned, t.jobs.Load())
+}
+
+// fill copies the counts into the --summary-json object
+func (t *scanTally) fill(s *runSummary) {
+	t.mu.Lock()
+	hosts, scanned := len(t.hosts), len(t.scanned)
+	t.mu.Unlock()
+	findings := t.findings.Load()
+	s.Jobs = t.jobs.Load()
+	s.Findings = &findings
+	s.HostsScanned = &scanned
+	s.HostsWithFindings = &hosts
+}
+
+// techOutput counts the output lines of the httpx jobs per tech, to tell which tech scans hit something
+type techOutput struct {
+	mu    sync.Mutex
+	jobs  map[string]int
+	lines map[string]int
+}
+
+func newTechOutput() *techOutput {
+	return &techOutput{jobs: make(map[string]int), lines: make(map[string]int)}
+}
+
+// record counts a finished job of tech that printed lines output lines
+func (t *techOutput) record(tech string, lines int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.jobs[tech]++
+	t.lines[tech] += lines
+}
+
+// summary returns the techs with output, as "tech (N lines in J jobs)", and the techs whose jobs printed
+// nothing, both sorted by name
+func (t *techOutput) summary() ([]string, []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var hit, empty []string
+	for tech, jobs := range t.jobs {
+		if lines := t.lines[tech]; lines > 0 {
+			hit = append(hit, fmt.Sprintf("%s (%d lines in %d jobs)", tech, lines, jobs))
+		} else {
+			empty = append(empty, tech)
+		}
+	}
+	sort.Strings(hit)
+	sort.Strings(empty)
+	return hit, empty
+}
+
+// fill copies the job, line and tech counts into the --summary-json object
+func (t *techOutput) fill(s *runSummary) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var lines int64
+	for tech, jobs := range t.jobs {
+		s.Jobs += int64(jobs)
+		lines += int64(t.lines[tech])
+		if t.lines[tech] > 0 {
+			s.TechsWithOutput = append(s.TechsWithOutput, tech)
+		} else {
+			s.TechsWithoutOutput = append(s.TechsWithoutOutput, tech)
+		}
+	}
+	sort.Strings(s.TechsWithOutput)
+	sort.Strings(s.TechsWithoutOutput)
+	s.Lines = &lines
+}
+
+// runSummary is the --summary-json object printed as the last stdout line, so a calling script can read the
+// run stats with tail -1 | jq. Counts a command doesn't keep are left out.
+type runSummary struct {
+	Command            string   `json:"command"`
+	Seconds            float64  `json:"seconds"`
+	Jobs               int64    `json:"jobs"`
+	Findings           *int64   `json:"findings,omitempty"`
+	HostsScanned       *int     `json:"hosts_scanned,omitempty"`
+	HostsWithFindings  *int     `json:"hosts_with_findings,omitempty"`
+	Lines              *int64   `json:"lines,omitempty"`
+	TechsWithOutput    []string `json:"techs_with_output,omitempty"`
+	TechsWithoutOutput []string `json:"techs_without_output,omitempty"`
+	Stopped            string   `json:"stopped,omitempty"` // max-runtime, job-error or interrupted
+	ExitCode           int      `json:"exit_code"`
+}
+
+// String returns the summary as a single JSON line
+func (s runSummary) String() string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
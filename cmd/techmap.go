@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+)
+
+// parseTechMap reads --tech-map-output aliases from a "raw=canonical,..." list or a file with one
+// raw=canonical pair per line (blank lines and # comments skipped)
+func parseTechMap(input string) (map[string]string, error) {
+	if _, err := os.Stat(input); err == nil {
+		data, err := os.ReadFile(input)
+		if err != nil {
+			return nil, err
+		}
+		var entries []string
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line != "" && !strings.HasPrefix(line, "#") {
+				entries = append(entries, line)
+			}
+		}
+		input = strings.Join(entries, ",")
+	}
+	return parseKeyValueList(input, false)
+}
+
+// mapTechNames rewrites each name of a comma-separated tech list to its canonical alias, keeping unmapped names
+func mapTechNames(techs string, aliases map[string]string) string {
+	if len(aliases) == 0 {
+		return techs
+	}
+	names := strings.Split(techs, ",")
+	for i, name := range names {
+		if canonical, ok := aliases[strings.ToLower(name)]; ok {
+			names[i] = canonical
+		}
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// techsCmd represents the techs command
+var techsCmd = &cobra.Command{
+	Use:   "techs",
+	Short: "List the unique technology names found in techfinder JSON read from stdin, optionally caching them for shell completion.",
+	Long: `The 'techs' command reads JSON (objects with {"host":..., "tech":[...]}) from stdin and prints every unique normalized technology name, one per line.
+
+With --save the names are merged into the tech vocabulary file used for tab-completion of --include-tech and --exclude-tech.
+
+Examples:
+  cat techfinder-output.json | vulntechfinder techs
+  cat techfinder-output.json | vulntechfinder techs --save
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		save, _ := cmd.Flags().GetBool("save")
+
+		seen := make(map[string]bool)
+		decoder := json.NewDecoder(os.Stdin)
+		for {
+			var techData TechData
+			if err := decoder.Decode(&techData); err == io.EOF {
+				break
+			} else if err != nil {
+				fmt.Printf("Error decoding JSON: %s\n", err)
+				os.Exit(1)
+			}
+
+			for _, tech := range normalizeTechs(techData.Tech, "first") {
+				seen[tech] = true
+			}
+		}
+
+		var names []string
+		for name := range seen {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			fmt.Println(name)
+		}
+
+		if save {
+			path, err := saveTechVocabulary(names)
+			if err != nil {
+				fmt.Printf("Error saving tech vocabulary: %s\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Saved %d technologies to %s\n", len(names), path)
+		}
+	},
+}
+
+// Values of --tech-segment, picking the name of a CPE-like "vendor:product:version" tech entry
+var techSegments = []string{"first", "product", "vendor-product"}
+
+// splitTech splits a "name:version" tech entry into its name and version. For a "vendor:product:version" entry,
+// segment "first" keeps the vendor as the name (and "product:version" as the version), "product" uses the product
+// and "vendor-product" joins both, e.g. "apache:tomcat:9" gives "tomcat" or "apache-tomcat" with version "9".
+func splitTech(t, segment string) (string, string) {
+	parts := strings.SplitN(t, ":", 2)
+	name, version := strings.TrimSpace(parts[0]), ""
+	if len(parts) == 2 {
+		version = strings.TrimSpace(parts[1])
+	}
+
+	if rest := strings.SplitN(version, ":", 2); segment != "first" && len(rest) == 2 {
+		product := strings.TrimSpace(rest[0])
+		if segment == "vendor-product" {
+			product = name + "-" + product
+		}
+		return product, strings.TrimSpace(rest[1])
+	}
+	return name, version
+}
+
+// normalizeTechs extracts the lowercase name (per splitTech) from each tech entry, ignoring names with spaces
+func normalizeTechs(techs []string, segment string) []string {
+	return techEntryNames(techs, segment, false)
+}
+
+// techEntryNames extracts the name (per splitTech) from each tech entry, lowercased unless caseSensitive,
+// ignoring names with spaces
+func techEntryNames(techs []string, segment string, caseSensitive bool) []string {
+	var names []string
+	for _, t := range techs {
+		name, _ := splitTech(t, segment)
+		if name == "" || strings.Contains(name, " ") {
+			continue
+		}
+		names = append(names, techCase(name, caseSensitive))
+	}
+	return names
+}
+
+// missingRequiredTechs returns the entries of required that are not among the names of techs, compared
+// exactly with --case-sensitive
+func missingRequiredTechs(techs, required []string, segment string, caseSensitive bool) []string {
+	names := techEntryNames(techs, segment, caseSensitive)
+	var missing []string
+	for _, tech := range required {
+		if tech != "" && !contains(names, tech) {
+			missing = append(missing, tech)
+		}
+	}
+	return missing
+}
+
+// techVocabularyPath returns the location of the cached tech vocabulary file
+func techVocabularyPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "vulntechfinder", "techs.txt"), nil
+}
+
+// loadTechVocabulary reads the cached tech vocabulary, returning nil if it does not exist
+func loadTechVocabulary() []string {
+	path, err := techVocabularyPath()
+	if err != nil {
+		return nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var techs []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if tech := strings.TrimSpace(scanner.Text()); tech != "" {
+			techs = append(techs, tech)
+		}
+	}
+	return techs
+}
+
+// saveTechVocabulary merges names into the cached tech vocabulary file and returns its path
+func saveTechVocabulary(names []string) (string, error) {
+	path, err := techVocabularyPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+
+	merged := make(map[string]bool)
+	for _, tech := range loadTechVocabulary() {
+		merged[tech] = true
+	}
+	for _, tech := range names {
+		merged[tech] = true
+	}
+
+	var all []string
+	for tech := range merged {
+		all = append(all, tech)
+	}
+	sort.Strings(all)
+
+	return path, os.WriteFile(path, []byte(strings.Join(all, "\n")+"\n"), 0644)
+}
+
+// completeTechNames completes the last entry of a comma-separated tech list from the cached vocabulary,
+// falling back to file completion when no vocabulary has been saved yet
+func completeTechNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	vocabulary := loadTechVocabulary()
+	if len(vocabulary) == 0 {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+
+	prefix := ""
+	partial := toComplete
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix = toComplete[:i+1]
+		partial = toComplete[i+1:]
+	}
+
+	var suggestions []string
+	for _, tech := range vocabulary {
+		if strings.HasPrefix(tech, strings.ToLower(partial)) {
+			suggestions = append(suggestions, prefix+tech)
+		}
+	}
+	return suggestions, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	rootCmd.AddCommand(techsCmd)
+
+	techsCmd.Flags().Bool("save", false, "Merge the found technologies into the vocabulary file used for --include-tech/--exclude-tech completion")
+
+	for _, c := range []*cobra.Command{nucleiCmd, httpxCmd} {
+		c.RegisterFlagCompletionFunc("include-tech", completeTechNames)
+		c.RegisterFlagCompletionFunc("exclude-tech", completeTechNames)
+	}
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// techTemplatesDir returns <base>/<tech>/ and whether that directory exists, for the {tech-templates} placeholder;
+// the directory name is lowercase unless caseSensitive
+func techTemplatesDir(base, tech string, caseSensitive bool) (string, bool) {
+	dir := filepath.Join(base, techCase(tech, caseSensitive)) + string(filepath.Separator)
+	info, err := os.Stat(dir)
+	return dir, err == nil && info.IsDir()
+}
+
+// techTemplatesList returns the comma-separated template directories of techs, as accepted by nuclei -t
+func techTemplatesList(base string, techs []string, caseSensitive bool) string {
+	var dirs []string
+	for _, tech := range techs {
+		if dir, ok := techTemplatesDir(base, tech, caseSensitive); ok {
+			dirs = append(dirs, dir)
+		}
+	}
+	return strings.Join(dirs, ",")
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+)
+
+// parseTimeoutMap parses --timeout-map entries like "confluence=15m,default=5m", keeping the case of the tech
+// names with caseSensitive
+func parseTimeoutMap(input string, caseSensitive bool) (map[string]time.Duration, error) {
+	pairs, err := parseKeyValueList(input, caseSensitive)
+	if err != nil {
+		return nil, err
+	}
+
+	timeouts := make(map[string]time.Duration)
+	for tech, value := range pairs {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration for %s: %s", tech, err)
+		}
+		timeouts[tech] = d
+	}
+	return timeouts, nil
+}
+
+// jobTimeout returns the timeout for a job running techs: the longest per-tech override, where techs
+// without one use the "default" entry or the global --timeout. Zero means no timeout and wins over any limit.
+func jobTimeout(techs []string, global time.Duration, overrides map[string]time.Duration, caseSensitive bool) time.Duration {
+	base := global
+	if d, ok := overrides["default"]; ok {
+		base = d
+	}
+
+	var longest time.Duration
+	for _, tech := range techs {
+		d, ok := overrides[techCase(tech, caseSensitive)]
+		if !ok {
+			d = base
+		}
+		if d <= 0 {
+			return 0
+		}
+		if d > longest {
+			longest = d
+		}
+	}
+	return longest
+}
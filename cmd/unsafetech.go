@@ -0,0 +1,15 @@
+package cmd
+
+import "strings"
+
+// Values of --unsafe-tech-policy, deciding what happens to techs containing one of the --unsafe-tech-chars:
+// keep substitutes them as they are, drop skips them and escape inserts {tech} as one single-quoted sh word
+var unsafeTechPolicies = []string{"keep", "drop", "escape"}
+
+// Characters that break an unquoted {tech} in a sh command line or a nuclei DSL string, for --unsafe-tech-chars
+const defaultUnsafeTechChars = "\"'`;|&$<>()\\"
+
+// hasUnsafeChars reports whether tech contains any of chars
+func hasUnsafeChars(tech, chars string) bool {
+	return chars != "" && strings.ContainsAny(tech, chars)
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"bufio"
+	"math/rand"
+	"os"
+	"strings"
+)
+
+// Environment variable holding the job's User-Agent with --random-ua
+const userAgentEnv = "VULNTECHFINDER_UA"
+
+// User-Agents picked from by --random-ua when no --ua-file is given
+var defaultUserAgents = []string{
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:125.0) Gecko/20100101 Firefox/125.0",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Safari/605.1.15",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.2478.67",
+	"Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1",
+}
+
+// loadUserAgents reads one User-Agent per line from path, skipping blank lines and # comments,
+// or returns the built-in list when path is empty
+func loadUserAgents(path string) ([]string, error) {
+	if path == "" {
+		return defaultUserAgents, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var agents []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		ua := strings.TrimSpace(scanner.Text())
+		if ua != "" && !strings.HasPrefix(ua, "#") {
+			agents = append(agents, ua)
+		}
+	}
+	if len(agents) == 0 && scanner.Err() == nil {
+		return defaultUserAgents, nil
+	}
+	return agents, scanner.Err()
+}
+
+// randomUserAgent picks a User-Agent from agents, or returns "" when the list is empty
+func randomUserAgent(agents []string) string {
+	if len(agents) == 0 {
+		return ""
+	}
+	return agents[rand.Intn(len(agents))]
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// envAssignmentRegex matches a VAR=value word in front of a command
+var envAssignmentRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
+// validateRun does the --validate-only checks that go beyond the flag values: the tools the command template and
+// the --command-prefix wrapper run are on PATH, --input exists, and the files the run would create (keyed by flag
+// name) have an existing directory. It returns one message per problem.
+func validateRun(prefix, template, inputPath string, outputs map[string]string) []string {
+	var problems []string
+
+	for _, command := range []string{prefix, template} {
+		if tool := commandTool(command); tool != "" {
+			if _, err := exec.LookPath(tool); err != nil {
+				problems = append(problems, fmt.Sprintf("command %q not found in PATH", tool))
+			}
+		}
+	}
+
+	if inputPath != "" {
+		if _, err := os.Stat(inputPath); err != nil {
+			problems = append(problems, fmt.Sprintf("--input: %s", err))
+		}
+	}
+
+	for _, flag := range []string{"output", "resume", "seen-db"} {
+		path := outputs[flag]
+		if path == "" {
+			continue
+		}
+		dir := filepath.Dir(expandOutputPath(path, time.Now()))
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			problems = append(problems, fmt.Sprintf("--%s: directory %q does not exist", flag, dir))
+		}
+	}
+	return problems
+}
+
+// commandTool returns the first word of command after any VAR=value assignments, or "" if it has none
+func commandTool(command string) string {
+	for _, word := range strings.Fields(command) {
+		if !envAssignmentRegex.MatchString(word) {
+			return word
+		}
+	}
+	return ""
+}
+
+// reportValidation prints the --validate-only result and exits with 1 if there were problems
+func reportValidation(problems []string) {
+	for _, problem := range problems {
+		fmt.Printf("Error: %s\n", problem)
+	}
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+	fmt.Println("Configuration OK")
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Names allowed for --var placeholders
+var templateVarName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// parseTemplateVars parses repeatable --var name=value flags; names can't shadow the built-in placeholders
+func parseTemplateVars(entries []string) (map[string]string, error) {
+	vars := make(map[string]string)
+	for _, entry := range entries {
+		parts := strings.SplitN(entry, "=", 2)
+		name := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || !templateVarName.MatchString(name) {
+			return nil, fmt.Errorf("invalid --var %q (expected name=value with a name of letters, digits, _ or -)", entry)
+		}
+		for _, p := range placeholderRegistry {
+			if p.Name == "{"+name+"}" {
+				return nil, fmt.Errorf("--var %s would shadow the built-in %s placeholder", name, p.Name)
+			}
+		}
+		vars[name] = parts[1]
+	}
+	return vars, nil
+}
+
+// applyTemplateVars replaces each {name} of vars in template
+func applyTemplateVars(template string, vars map[string]string) string {
+	for name, value := range vars {
+		template = strings.Replace(template, "{"+name+"}", value, -1)
+	}
+	return template
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Comparison operators accepted by --tech-version-filter, two-character ones first so they match before "<" and ">"
+var versionOperators = []string{"<=", ">=", "!=", "==", "<", ">", "="}
+
+// versionConstraint is one --tech-version-filter entry such as "jira<9.4.0"
+type versionConstraint struct {
+	tech    string
+	op      string
+	version string
+}
+
+// parseVersionConstraints parses entries like "jira<9.4.0" or "confluence>=7.0,confluence<7.19" into constraints
+func parseVersionConstraints(entries []string) ([]versionConstraint, error) {
+	var constraints []versionConstraint
+	for _, entry := range entries {
+		for _, item := range strings.Split(entry, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+
+			parsed := false
+			for _, op := range versionOperators {
+				i := strings.Index(item, op)
+				if i <= 0 {
+					continue
+				}
+				tech := strings.ToLower(strings.TrimSpace(item[:i]))
+				version := strings.TrimSpace(item[i+len(op):])
+				if version == "" {
+					break
+				}
+				constraints = append(constraints, versionConstraint{tech: tech, op: op, version: version})
+				parsed = true
+				break
+			}
+			if !parsed {
+				return nil, fmt.Errorf("invalid constraint %q, expected <tech><op><version> with op one of %s", item, strings.Join(versionOperators, " "))
+			}
+		}
+	}
+	return constraints, nil
+}
+
+// techVersionAllowed reports whether a tech with the given version (the part after ":" in the tech entry) passes
+// the constraints. Techs without a constraint are dropped, as is a constrained tech with no detected version.
+func techVersionAllowed(constraints []versionConstraint, tech, version string) bool {
+	tech = strings.ToLower(tech)
+	constrained := false
+	for _, c := range constraints {
+		if c.tech != tech {
+			continue
+		}
+		constrained = true
+		if version == "" {
+			return false
+		}
+
+		cmp := compareVersions(version, c.version)
+		var ok bool
+		switch c.op {
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		case "!=":
+			ok = cmp != 0
+		default:
+			ok = cmp == 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return constrained
+}
+
+// compareVersions compares dotted versions like "9.4.0" and "v9.12", numerically per component where both are
+// numbers and as strings otherwise; missing components count as 0. A pre-release suffix such as "-rc1" or "rc1"
+// orders below the bare version, so 9.4.0-rc1 < 9.4.0, and pre-releases compare among themselves the same way.
+func compareVersions(a, b string) int {
+	coreA, preA := splitPrerelease(a)
+	coreB, preB := splitPrerelease(b)
+	if c := compareVersionParts(coreA, coreB); c != 0 {
+		return c
+	}
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	}
+	return compareVersionParts(preA, preB)
+}
+
+// splitPrerelease splits a version into its release part and pre-release suffix, which follows the first "-" or
+// starts at letters trailing the last component, as in "9.4.0rc1"; "+build" metadata is dropped
+func splitPrerelease(v string) (string, string) {
+	v = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(v)), "v")
+	v, _, _ = strings.Cut(v, "+")
+	if core, pre, found := strings.Cut(v, "-"); found {
+		return core, pre
+	}
+	last := v[strings.LastIndex(v, ".")+1:]
+	digits := len(last) - len(strings.TrimLeft(last, "0123456789"))
+	if digits > 0 && digits < len(last) {
+		cut := len(v) - len(last) + digits
+		return v[:cut], v[cut:]
+	}
+	return v, ""
+}
+
+// compareVersionParts compares the components of two version parts split on ".", "-" and "_"
+func compareVersionParts(a, b string) int {
+	split := func(v string) []string {
+		return strings.FieldsFunc(v, func(r rune) bool { return r == '.' || r == '-' || r == '_' })
+	}
+	pa, pb := split(a), split(b)
+
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		x, y := "0", "0"
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+
+		nx, errX := strconv.Atoi(x)
+		ny, errY := strconv.Atoi(y)
+		switch {
+		case errX == nil && errY == nil:
+			if nx != ny {
+				if nx < ny {
+					return -1
+				}
+				return 1
+			}
+		case x != y:
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
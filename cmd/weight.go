@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// parseTechWeights parses --tech-weight entries like "confluence=4,default=1", keeping the case of the tech
+// names with caseSensitive
+func parseTechWeights(input string, caseSensitive bool) (map[string]int, error) {
+	pairs, err := parseKeyValueList(input, caseSensitive)
+	if err != nil {
+		return nil, err
+	}
+
+	weights := make(map[string]int)
+	for tech, value := range pairs {
+		w, err := strconv.Atoi(value)
+		if err != nil || w < 1 {
+			return nil, fmt.Errorf("invalid weight for %s: %q (expected a positive integer)", tech, value)
+		}
+		weights[tech] = w
+	}
+	return weights, nil
+}
+
+// jobWeight returns how many of the capacity parallel slots a job running techs takes: the heaviest per-tech
+// weight, where techs without one use the "default" entry or 1. It is capped at capacity so every job can run.
+func jobWeight(techs []string, weights map[string]int, capacity int, caseSensitive bool) int64 {
+	base := 1
+	if w, ok := weights["default"]; ok {
+		base = w
+	}
+
+	heaviest := 1
+	for _, tech := range techs {
+		w, ok := weights[techCase(tech, caseSensitive)]
+		if !ok {
+			w = base
+		}
+		if w > heaviest {
+			heaviest = w
+		}
+	}
+	if heaviest > capacity {
+		heaviest = capacity
+	}
+	return int64(heaviest)
+}
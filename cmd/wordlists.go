@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// wordlistResolver maps tech names to wordlist paths, caching lookups so each tech is only stat'ed once
+// and remembering which techs had no wordlist
+type wordlistResolver struct {
+	mu            sync.Mutex
+	dir           string
+	paths         map[string]string
+	caseSensitive bool // --case-sensitive: --path-map entries only match techs of the same case
+	cache         map[string]string
+	missing       map[string]bool
+}
+
+func newWordlistResolver(dir string, paths map[string]string, caseSensitive bool) *wordlistResolver {
+	return &wordlistResolver{
+		dir:           dir,
+		paths:         paths,
+		caseSensitive: caseSensitive,
+		cache:         make(map[string]string),
+		missing:       make(map[string]bool),
+	}
+}
+
+// resolve returns the wordlist path for tech and whether one was found. A --path-map entry for tech wins,
+// otherwise candidates are tried in order:
+// 1) tech as provided (maybe user passed "jenkins.txt")
+// 2) <dir>/<tech>
+// 3) <dir>/<tech>.txt
+func (w *wordlistResolver) resolve(tech string) (string, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if path, ok := w.cache[tech]; ok {
+		return path, path != ""
+	}
+
+	if path, ok := w.paths[techCase(tech, w.caseSensitive)]; ok {
+		w.cache[tech] = path
+		return path, true
+	}
+
+	try1 := filepath.Join(w.dir, tech)
+	candidates := []string{tech, try1}
+	if !strings.HasSuffix(strings.ToLower(try1), ".txt") {
+		candidates = append(candidates, try1+".txt")
+	}
+
+	for _, candidate := range candidates {
+		if fileExists(candidate) {
+			w.cache[tech] = candidate
+			return candidate, true
+		}
+	}
+
+	w.cache[tech] = ""
+	w.missing[tech] = true
+	return "", false
+}
+
+// missingTechs returns the sorted techs for which no wordlist was found
+func (w *wordlistResolver) missingTechs() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var techs []string
+	for tech := range w.missing {
+		techs = append(techs, tech)
+	}
+	sort.Strings(techs)
+	return techs
+}
+
+// parsePathMap reads a --path-map file with one tech=/path/to/wordlist pair per line (blank lines and # comments
+// skipped). Every mapped wordlist must exist, so a typo is reported up front instead of on each httpx run.
+// Tech names are lowercased unless caseSensitive.
+func parsePathMap(path string, caseSensitive bool) (map[string]string, error) {
+	paths := make(map[string]string)
+	if path == "" {
+		return paths, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		tech, wordlist := strings.TrimSpace(parts[0]), ""
+		if len(parts) == 2 {
+			wordlist = strings.TrimSpace(parts[1])
+		}
+		if tech == "" || wordlist == "" {
+			return nil, fmt.Errorf("line %d: invalid entry %q (expected tech=/path/to/wordlist)", i+1, line)
+		}
+		if !fileExists(wordlist) {
+			return nil, fmt.Errorf("line %d: wordlist %s for %s does not exist", i+1, wordlist, tech)
+		}
+		paths[techCase(tech, caseSensitive)] = wordlist
+	}
+	return paths, nil
+}
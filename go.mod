@@ -2,9 +2,11 @@ module github.com/rix4uni/vulntechfinder
 
 go 1.25.1
 
-require github.com/spf13/cobra v1.10.1
-
 require (
-	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/spf13/pflag v1.0.9 // indirect
+	github.com/klauspost/compress v1.18.0
+	github.com/spf13/cobra v1.10.1
+	github.com/spf13/pflag v1.0.9
+	golang.org/x/sync v0.9.0
 )
+
+require github.com/inconshreveable/mousetrap v1.1.0 // indirect